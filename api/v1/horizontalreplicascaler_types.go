@@ -1,17 +1,108 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Important: Run "make" to regenerate code after modifying this file.
 
+const (
+	// PausedAnnotation freezes the scaler when it is "true", leaving the replicas of the target unchanged.
+	PausedAnnotation = "scaling.rrethy.com/paused"
+	// PinnedReplicasAnnotation pins the replicas of the target to its value, ignoring the metrics and replica limits.
+	PinnedReplicasAnnotation = "scaling.rrethy.com/pinned-replicas"
+	// UntilAnnotation is the RFC 3339 time the paused and pinned replicas annotations expire at, e.g. "2024-06-01T12:00:00Z".
+	// The annotations are removed from the scaler once they expire.
+	UntilAnnotation = "scaling.rrethy.com/until"
+)
+
+const (
+	// ConditionTypeOverridden is the type of the condition reporting whether scaling is paused or pinned by annotations.
+	ConditionTypeOverridden = "Overridden"
+	// ConditionTypeFlapping is the type of the condition reporting whether the replicas of the target are oscillating.
+	ConditionTypeFlapping = "Flapping"
+)
+
 // MetricType is the type of metric to use.
 type MetricType string
 
 const (
-	StaticMetricType     MetricType = "static"
-	PrometheusMetricType MetricType = "prometheus"
+	StaticMetricType          MetricType = "static"
+	PrometheusMetricType      MetricType = "prometheus"
+	KafkaMetricType           MetricType = "kafka"
+	RabbitMQMetricType        MetricType = "rabbitmq"
+	CronMetricType            MetricType = "cron"
+	KubeObjectCountMetricType MetricType = "kube-object-count"
+	ExpressionMetricType      MetricType = "expression"
+	OTLPMetricType            MetricType = "otlp"
+	GraphiteMetricType        MetricType = "graphite"
+	InfluxDBMetricType        MetricType = "influxdb"
+	ElasticsearchMetricType   MetricType = "elasticsearch"
+	SQLMetricType             MetricType = "sql"
+	ScalerRefMetricType       MetricType = "scaler-ref"
+	ResourceMetricType        MetricType = "resource"
+)
+
+// AggregationType is how the replica recommendations of the metrics are combined.
+type AggregationType string
+
+const (
+	// MaxAggregationType uses the largest recommendation.
+	MaxAggregationType AggregationType = "max"
+	// MinAggregationType uses the smallest recommendation.
+	MinAggregationType AggregationType = "min"
+	// AverageAggregationType uses the average of the recommendations, rounded up.
+	AverageAggregationType AggregationType = "average"
+	// MedianAggregationType uses the median of the recommendations, rounded up.
+	MedianAggregationType AggregationType = "median"
+	// WeightedAggregationType uses the average of the recommendations weighted by the weight of each metric, rounded up.
+	WeightedAggregationType AggregationType = "weighted"
+)
+
+// MetricRole is the role a metric plays in deciding the replicas.
+type MetricRole string
+
+const (
+	// FloorMetricRole makes the recommendation of the metric a lower bound instead of driving scaling.
+	FloorMetricRole MetricRole = "floor"
+	// CeilingMetricRole makes the recommendation of the metric an upper bound instead of driving scaling.
+	CeilingMetricRole MetricRole = "ceiling"
+)
+
+// ControllerType is how the value of a metric is converted into replicas.
+type ControllerType string
+
+const (
+	// ProportionalControllerType scales the replicas proportionally to the ratio of the metric value to its target.
+	ProportionalControllerType ControllerType = "proportional"
+	// PIDControllerType scales the replicas with a PID controller driven by the relative error of the metric value from its target.
+	PIDControllerType ControllerType = "pid"
+	// StepControllerType adjusts the replicas by the step matching the relative error of the metric value from its target.
+	StepControllerType ControllerType = "step"
+)
+
+// ForecasterType is the type of forecaster used for predictive scaling.
+type ForecasterType string
+
+const (
+	// LinearForecasterType extends a least squares line fitted to the history.
+	LinearForecasterType ForecasterType = "linear"
+	// HoltWintersForecasterType uses additive Holt-Winters smoothing to forecast a trend with a repeating season.
+	HoltWintersForecasterType ForecasterType = "holt-winters"
+)
+
+// TargetType is the type of target to scale towards.
+type TargetType string
+
+const (
+	// ValueTargetType scales the current replicas by the ratio of the metric value to the target value.
+	ValueTargetType TargetType = "value"
+	// PodAverageTargetType scales so that the metric value divided by the replicas is the target value.
+	PodAverageTargetType TargetType = "pod-average"
+	// UtilizationTargetType scales so that the metric value as a percentage of the container requests
+	// of the target's pods is the target value.
+	UtilizationTargetType TargetType = "utilization"
 )
 
 type ScaleTargetRef struct {
@@ -29,6 +120,21 @@ type ScaleTargetRef struct {
 	Name string `json:"name"`
 }
 
+// StabilizationType is how the recommendations within a stabilization window are combined.
+type StabilizationType string
+
+const (
+	// MaxStabilizationType uses the largest recommendation in the window.
+	MaxStabilizationType StabilizationType = "max"
+	// MinStabilizationType uses the smallest recommendation in the window.
+	MinStabilizationType StabilizationType = "min"
+	// PercentileStabilizationType uses a percentile of the recommendations in the window.
+	PercentileStabilizationType StabilizationType = "percentile"
+	// EWMAStabilizationType uses an exponentially weighted moving average of the recommendations in the window,
+	// with the window as its time constant.
+	EWMAStabilizationType StabilizationType = "ewma"
+)
+
 // ScalingRules defines the scaling rules for how many replicas to scaling up or down.
 type ScalingRules struct {
 	// StabilizationWindowSeconds is the number of seconds to wait before considering the system stable.
@@ -40,6 +146,25 @@ type ScalingRules struct {
 	// +kubebuilder:validation:Default=0s
 	StabilizationWindow metav1.Duration `json:"stabilizationWindowSeconds,omitempty"`
 
+	// StabilizationType is how the recommendations within the stabilization window are combined.
+	// It defaults to max for scaling down and min for scaling up, which only scale once every recommendation in the window agrees.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=max;min;percentile;ewma
+	StabilizationType StabilizationType `json:"stabilizationType,omitempty"`
+
+	// StabilizationPercentile is the percentile of the recommendations used by the percentile stabilization type,
+	// e.g. 90 to scale down to the p90 of recent recommendations. It defaults to 90.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	StabilizationPercentile int32 `json:"stabilizationPercentile,omitempty"`
+
+	// Cooldown is the minimum time since the last scale event of the target, in either direction, before it is scaled
+	// in this direction again. Unlike the stabilization window it limits how often the target is scaled rather than
+	// smoothing the recommendations. Scaling to and from zero ignores the cooldown.
+	// +kubebuilder:validation:Optional
+	Cooldown metav1.Duration `json:"cooldown,omitempty"`
+
 	// TODO: Something akin to HPA scaling policies.
 }
 
@@ -52,6 +177,33 @@ type ScalingBehavior struct {
 	// ScaleDown is the scaling behavior for scaling down.
 	// +kubebuilder:validation:Optional
 	ScaleDown ScalingRules `json:"scaleDown,omitempty"`
+
+	// FlappingDetection detects the replicas of the target oscillating and dampens it by extending the scale down
+	// stabilization window until the oscillation stops.
+	// +kubebuilder:validation:Optional
+	FlappingDetection *FlappingDetection `json:"flappingDetection,omitempty"`
+
+	// ScaleDownDelayAfterScaleUp is the minimum time since the target was last scaled up before it is scaled down.
+	// +kubebuilder:validation:Optional
+	ScaleDownDelayAfterScaleUp metav1.Duration `json:"scaleDownDelayAfterScaleUp,omitempty"`
+}
+
+// FlappingDetection defines when the replicas of the target are considered to be oscillating and how it is dampened.
+type FlappingDetection struct {
+	// Window is how far back scale events are considered. It defaults to 10m.
+	// +kubebuilder:validation:Optional
+	Window metav1.Duration `json:"window,omitempty"`
+
+	// MaxReversals is the number of times the direction of scaling may reverse within the window before the target is
+	// considered to be flapping. It defaults to 3.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxReversals int32 `json:"maxReversals,omitempty"`
+
+	// ScaleDownStabilizationWindow is the scale down stabilization window used while the target is flapping, if it is
+	// longer than the configured one. It defaults to the window.
+	// +kubebuilder:validation:Optional
+	ScaleDownStabilizationWindow metav1.Duration `json:"scaleDownStabilizationWindow,omitempty"`
 }
 
 // Fallback defines the fallback behavior when failures occur.
@@ -68,28 +220,259 @@ type Fallback struct {
 type TargetSec struct {
 	// Type is the type of the target.
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=pod-average;value
-	Type string `json:"type"`
+	// +kubebuilder:validation:Enum=pod-average;value;utilization
+	Type TargetType `json:"type"`
 
 	// Value is the value of the target.
+	// For utilization targets, this is a percentage of the requests, e.g. "70".
 	// +kubebuilder:validation:Required
 	Value string `json:"value"`
+
+	// Resource is the container resource whose requests the metric value is compared against for utilization targets, e.g. cpu.
+	// The metric value must be the total usage across the pods in the same unit, which is cores for cpu and bytes for memory,
+	// such as the value of a resource metric. It is also the resource whose usage resource metrics sum.
+	// +kubebuilder:validation:Optional
+	Resource corev1.ResourceName `json:"resource,omitempty"`
+
+	// Container limits the requests of utilization targets, and the usage of resource metrics, to the containers with this name.
+	// By default the requests of every container of the pods are summed.
+	// +kubebuilder:validation:Optional
+	Container string `json:"container,omitempty"`
+
+	// PerPod marks the metric value of a pod-average target as the sum of a per-pod metric, such as CPU usage or throughput.
+	// The value is then averaged over the ready pods of the target only. When scaling up, unready and missing pods
+	// are assumed to report 0, and when scaling down, missing pods are assumed to be at the target, so pods that are
+	// starting or not reporting don't cause the target to scale further than needed.
+	// +kubebuilder:validation:Optional
+	PerPod bool `json:"perPod,omitempty"`
+}
+
+// Predictive defines how metrics are forecasted to scale ahead of demand.
+// The history of each metric is at most 4096 steps, since the histories are saved in a ConfigMap.
+// +kubebuilder:validation:XValidation:rule="!has(self.history) || duration(self.history).getSeconds() <= 4096 * (has(self.step) ? duration(self.step).getSeconds() : 60)",message="history must be at most 4096 steps"
+type Predictive struct {
+	// Forecaster is the forecaster fitted to the history of each metric.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=linear;holt-winters
+	// +kubebuilder:validation:Default=linear
+	Forecaster ForecasterType `json:"forecaster,omitempty"`
+
+	// Lookahead is how far in the future metrics are forecasted. It must be positive.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="lookahead must be positive"
+	Lookahead metav1.Duration `json:"lookahead"`
+
+	// Step is the interval the history of each metric is sampled at. It must be at least 1s and defaults to 1m.
+	// The history is saved at most once per step.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1s')",message="step must be at least 1s"
+	Step metav1.Duration `json:"step,omitempty"`
+
+	// Season is the length of the repeating pattern of the metrics for the holt-winters forecaster. It defaults to 24h.
+	// +kubebuilder:validation:Optional
+	Season metav1.Duration `json:"season,omitempty"`
+
+	// History is how long the history of each metric is kept. It defaults to 1h for the linear forecaster
+	// and two seasons for the holt-winters forecaster, which needs at least two seasons of history to forecast.
+	// +kubebuilder:validation:Optional
+	History metav1.Duration `json:"history,omitempty"`
+}
+
+// PID defines the parameters of the PID controller of a metric.
+// The error is the relative difference of the metric value from its target, e.g. 0.5 when the value is 50% above the target,
+// and the output of the controller is the relative change to the current replicas. The default gains of a Kp of 1 and
+// Ki and Kd of 0 scale the same as the proportional controller.
+type PID struct {
+	// Kp is the proportional gain. It defaults to 1.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	Kp string `json:"kp,omitempty"`
+
+	// Ki is the integral gain, applied to the error integrated over time in seconds. It defaults to 0.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	Ki string `json:"ki,omitempty"`
+
+	// Kd is the derivative gain, applied to the change of the error per second. It defaults to 0.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	Kd string `json:"kd,omitempty"`
+
+	// IntegralLimit bounds the absolute value of the integral to prevent it from winding up while the target
+	// can't keep up, e.g. at MaxReplicas. The integral is unlimited by default.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	IntegralLimit string `json:"integralLimit,omitempty"`
+
+	// DerivativeSmoothing is the fraction of the previous derivative kept when updating it, e.g. "0.8", which dampens
+	// noise in the metric. It must be less than 1 and defaults to 0, which doesn't smooth the derivative.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^0?(\.[0-9]+)?$`
+	DerivativeSmoothing string `json:"derivativeSmoothing,omitempty"`
+}
+
+// ScalingStep adjusts the replicas when the relative difference of a metric value from its target is within its bounds,
+// e.g. a LowerBound of "0.2" and UpperBound of "0.4" matches values 20% to 40% above the target.
+type ScalingStep struct {
+	// LowerBound is the inclusive lower bound of the relative difference of the metric value from its target.
+	// The step has no lower bound by default.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^-?(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	LowerBound string `json:"lowerBound,omitempty"`
+
+	// UpperBound is the exclusive upper bound of the relative difference of the metric value from its target.
+	// The step has no upper bound by default.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^-?(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	UpperBound string `json:"upperBound,omitempty"`
+
+	// Adjustment is the number of replicas added to the current replicas, or removed when it is negative.
+	// +kubebuilder:validation:Required
+	Adjustment int32 `json:"adjustment"`
+}
+
+// SpikeFilter defines how values which deviate too far from the recent values of a metric are rejected.
+type SpikeFilter struct {
+	// Factor is how many times greater or smaller than the median of the recent values a value may be before it is
+	// rejected, e.g. "10". It must be greater than 1.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	Factor string `json:"factor"`
+
+	// Samples is the number of recent accepted values the median is computed from. It defaults to 5.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	Samples int32 `json:"samples,omitempty"`
 }
 
 // MetricSpec defines a metric to consider for scaling.
 type MetricSpec struct {
+	// Name is the name of the metric which is used to reference its value from expression metrics.
+	// It must be unique within the metrics of the scaler.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_]*$`
+	Name string `json:"name,omitempty"`
+
 	// Type is the type of metric to use.
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=static;prometheus
+	// +kubebuilder:validation:Enum=static;prometheus;kafka;rabbitmq;cron;kube-object-count;expression;otlp;graphite;influxdb;elasticsearch;sql;scaler-ref;resource
 	Type MetricType `json:"type"`
 
 	// Config is a map of configuration values for the metric.
 	// +kubebuilder:validation:Optional
 	Config map[string]string `json:"config"`
 
+	// SecretRef is a reference to a Secret in the same namespace as the scaler.
+	// The data of the Secret is merged into Config, taking precedence over any existing keys.
+	// This should be used for credentials instead of putting them directly in Config.
+	// +kubebuilder:validation:Optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Role makes the metric bound the replicas instead of driving scaling.
+	// Floor metrics raise the aggregated recommendation of the other metrics to at least their recommendation,
+	// and ceiling metrics lower it to at most their recommendation. Ceilings take precedence over floors.
+	// By default the metric drives scaling, except for cron metrics which default to floor so the 0 they recommend
+	// outside of their windows doesn't lower the aggregation.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=floor;ceiling
+	Role MetricRole `json:"role,omitempty"`
+
+	// Weight is the weight of the metric for the weighted aggregation, e.g. "2.5". It defaults to 1.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	Weight string `json:"weight,omitempty"`
+
+	// Activation is the value the metric must be above to be active, e.g. "5". It defaults to 0.
+	// It is only used when the MinReplicas of the scaler is 0, and a target is only scaled to zero when
+	// none of its metrics are active. Ceiling metrics are never active.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	Activation string `json:"activation,omitempty"`
+
+	// Tolerance is the ratio the metric value may differ from its target by without changing the replicas,
+	// e.g. "0.1" ignores metric values within 10% of the target. It overrides the tolerance of the scaler.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	Tolerance string `json:"tolerance,omitempty"`
+
+	// MinValid is the smallest valid value of the metric. Smaller values are rejected and replaced by the last
+	// accepted value of the metric.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^-?(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	MinValid string `json:"minValid,omitempty"`
+
+	// MaxValid is the largest valid value of the metric. Larger values are rejected and replaced by the last
+	// accepted value of the metric.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^-?(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	MaxValid string `json:"maxValid,omitempty"`
+
+	// SpikeFilter rejects values which deviate too far from the median of the recent values of the metric and replaces
+	// them by the last accepted value, unless the next value deviates too far as well, confirming the change.
+	// +kubebuilder:validation:Optional
+	SpikeFilter *SpikeFilter `json:"spikeFilter,omitempty"`
+
+	// Controller is how the value of the metric is converted into replicas.
+	// Metrics whose value is the number of replicas, such as static and cron, only support the proportional controller.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=proportional;pid;step
+	// +kubebuilder:validation:Default=proportional
+	Controller ControllerType `json:"controller,omitempty"`
+
+	// PID is the parameters of the PID controller when the controller is pid.
+	// Within the tolerance of the target, the error of the PID controller is treated as 0.
+	// +kubebuilder:validation:Optional
+	PID *PID `json:"pid,omitempty"`
+
+	// Steps are the scaling steps of the metric when the controller is step. The first step whose bounds contain the
+	// relative difference of the metric value from its target is applied, and the current replicas are kept when no
+	// step matches. The tolerance is not applied to steps since their bounds already define when to scale.
+	// +kubebuilder:validation:Optional
+	Steps []ScalingStep `json:"steps,omitempty"`
+
 	// Target is the target specification for the metric.
+	// It is required for every metric type except cron and scaler-ref, whose values are the number of replicas.
+	// +kubebuilder:validation:Optional
+	Target *TargetSec `json:"target,omitempty"`
+}
+
+// ReplicaSchedule overrides the MinReplicas and MaxReplicas of the scaler while it is active.
+// It is either a recurring window between a Start and End cron expression, or an absolute window between a StartTime
+// and EndTime.
+type ReplicaSchedule struct {
+	// Name is the name of the schedule which is reported in the status while it is active.
 	// +kubebuilder:validation:Required
-	Target TargetSec `json:"target"`
+	Name string `json:"name"`
+
+	// Start is the cron expression the recurring window starts at, e.g. "0 9 * * 1-5".
+	// +kubebuilder:validation:Optional
+	Start string `json:"start,omitempty"`
+
+	// End is the cron expression the recurring window ends at, e.g. "0 17 * * 1-5".
+	// +kubebuilder:validation:Optional
+	End string `json:"end,omitempty"`
+
+	// Timezone is the timezone the cron expressions are evaluated in, e.g. "America/Toronto". It defaults to UTC.
+	// +kubebuilder:validation:Optional
+	Timezone string `json:"timezone,omitempty"`
+
+	// StartTime is the time the absolute window starts at. The window has no start by default.
+	// +kubebuilder:validation:Optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// EndTime is the time the absolute window ends at. The window has no end by default.
+	// +kubebuilder:validation:Optional
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+
+	// MinReplicas overrides the MinReplicas of the scaler while the schedule is active.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas overrides the MaxReplicas of the scaler while the schedule is active.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
 }
 
 // HorizontalReplicaScalerSpec defines the desired state of HorizontalReplicaScaler.
@@ -104,10 +487,22 @@ type HorizontalReplicaScalerSpec struct {
 	ScaleTargetRef ScaleTargetRef `json:"scaleTargetRef"`
 
 	// MinReplicas is the lower limit for the number of replicas to which the target can be scaled.
+	// A value of 0 allows the target to scale to zero once every metric is below its activation threshold
+	// for the CooldownPeriod, and to scale back up to at least MinActiveReplicas when any metric is above it.
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Minimum=0
 	MinReplicas int32 `json:"minReplicas"`
 
+	// MinActiveReplicas is the lower limit for the number of replicas while any metric is active when MinReplicas is 0.
+	// It is also the number of replicas the target is woken up to from zero. It defaults to 1.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MinActiveReplicas int32 `json:"minActiveReplicas,omitempty"`
+
+	// CooldownPeriod is how long every metric must be inactive before the target is scaled to zero when MinReplicas is 0.
+	// +kubebuilder:validation:Optional
+	CooldownPeriod metav1.Duration `json:"cooldownPeriod,omitempty"`
+
 	// MaxReplicas is the upper limit for the number of replicas to which the target can be scaled.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Minimum=1
@@ -122,6 +517,35 @@ type HorizontalReplicaScalerSpec struct {
 	// +kubebuilder:validation:Optional
 	ScalingBehavior ScalingBehavior `json:"scalingBehavior,omitempty"`
 
+	// InitializationPeriod is how long after starting a pod is treated as unready for per-pod pod-average targets,
+	// since pods often report skewed metrics while they are starting.
+	// +kubebuilder:validation:Optional
+	InitializationPeriod metav1.Duration `json:"initializationPeriod,omitempty"`
+
+	// Aggregation is how the replica recommendations of the metrics that drive scaling are combined.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=max;min;average;median;weighted
+	// +kubebuilder:validation:Default=max
+	Aggregation AggregationType `json:"aggregation,omitempty"`
+
+	// Tolerance is the ratio a metric value may differ from its target by without changing the replicas,
+	// e.g. "0.1" ignores metric values within 10% of the target. Metrics may override it.
+	// Metrics whose value is the number of replicas, such as static and cron, ignore the tolerance.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0|[1-9][0-9]*)?(\.[0-9]+)?$`
+	Tolerance string `json:"tolerance,omitempty"`
+
+	// Predictive forecasts each metric and uses the forecasted replica recommendation when it is greater than the current one.
+	// The history of the metrics is kept in a ConfigMap named after the scaler with a "-history" suffix.
+	// Metrics whose value is the number of replicas, such as static and cron, are not forecasted.
+	// +kubebuilder:validation:Optional
+	Predictive *Predictive `json:"predictive,omitempty"`
+
+	// Schedules override the MinReplicas and MaxReplicas while they are active. When several active schedules override
+	// the same limit, the highest one is used.
+	// +kubebuilder:validation:Optional
+	Schedules []ReplicaSchedule `json:"schedules,omitempty"`
+
 	// Fallback is the fallback behavior for the autoscaler when metrics fail.
 	// The fallback applies to each metric individually.
 	// +kubebuilder:validation:Optional
@@ -144,11 +568,130 @@ type ScaleEvent struct {
 	Timestamp metav1.Time `json:"timestamp"`
 }
 
+// MetricStatus is the observed state of a metric when the scaler was last reconciled.
+type MetricStatus struct {
+	// Name is the name of the metric, if it has one.
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Type is the type of the metric.
+	// +kubebuilder:validation:Required
+	Type MetricType `json:"type"`
+
+	// Value is the value of the metric.
+	// +kubebuilder:validation:Optional
+	Value string `json:"value,omitempty"`
+
+	// Replicas is the number of replicas recommended by the metric.
+	// +kubebuilder:validation:Optional
+	Replicas int32 `json:"replicas"`
+
+	// Forecast is the forecasted value of the metric at the lookahead of predictive scaling.
+	// +kubebuilder:validation:Optional
+	Forecast string `json:"forecast,omitempty"`
+
+	// ForecastReplicas is the number of replicas recommended by the forecasted value of the metric.
+	// +kubebuilder:validation:Optional
+	ForecastReplicas int32 `json:"forecastReplicas,omitempty"`
+
+	// Reason explains the recommendation when it isn't derived directly from the value, e.g. WithinTolerance.
+	// +kubebuilder:validation:Optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// PIDState is the state of the PID controller of a metric, which is kept in the status so it survives restarts of the
+// controller manager.
+type PIDState struct {
+	// Metric is the name of the metric, or "metric-" followed by its index for metrics without a name.
+	// +kubebuilder:validation:Required
+	Metric string `json:"metric"`
+
+	// Integral is the error integrated over time in seconds.
+	// +kubebuilder:validation:Optional
+	Integral string `json:"integral,omitempty"`
+
+	// Error is the error of the last update of the controller.
+	// +kubebuilder:validation:Optional
+	Error string `json:"error,omitempty"`
+
+	// Derivative is the smoothed derivative of the error per second.
+	// +kubebuilder:validation:Optional
+	Derivative string `json:"derivative,omitempty"`
+
+	// LastUpdateTime is the time of the last update of the controller.
+	// +kubebuilder:validation:Required
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// OutlierState is the state of the outlier rejection of a metric, which is kept in the status so it survives restarts
+// of the controller manager.
+type OutlierState struct {
+	// Metric is the name of the metric, or "metric-" followed by its index for metrics without a name.
+	// +kubebuilder:validation:Required
+	Metric string `json:"metric"`
+
+	// RecentValues is the most recent accepted values of the metric, oldest first.
+	// +kubebuilder:validation:Optional
+	RecentValues []string `json:"recentValues,omitempty"`
+
+	// Suspect is true when the previous value of the metric was rejected as a spike.
+	// +kubebuilder:validation:Optional
+	Suspect bool `json:"suspect,omitempty"`
+
+	// Rejections is the number of values of the metric which were rejected.
+	// +kubebuilder:validation:Optional
+	Rejections int32 `json:"rejections,omitempty"`
+}
+
 // HorizontalReplicaScalerStatus defines the observed state of HorizontalReplicaScaler.
 type HorizontalReplicaScalerStatus struct {
 	// DesiredReplicas is the number of replicas the target should be scaled to.
 	// +kubebuilder:validation:Optional
 	DesiredReplicas int32 `json:"desiredReplicas"`
+
+	// CurrentReplicas is the number of replicas of the target when the scaler was last reconciled.
+	// +kubebuilder:validation:Optional
+	CurrentReplicas int32 `json:"currentReplicas"`
+
+	// LastScaleTime is the last time the replicas of the target were changed by the scaler.
+	// +kubebuilder:validation:Optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+
+	// LastScaleUpTime is the last time the replicas of the target were increased by the scaler.
+	// +kubebuilder:validation:Optional
+	LastScaleUpTime *metav1.Time `json:"lastScaleUpTime,omitempty"`
+
+	// ScaleHistory is the replicas the target was scaled to within the window of the flapping detection, oldest first.
+	// +kubebuilder:validation:Optional
+	ScaleHistory []ScaleEvent `json:"scaleHistory,omitempty"`
+
+	// LastActiveTime is the last time any metric was active when MinReplicas is 0.
+	// +kubebuilder:validation:Optional
+	LastActiveTime *metav1.Time `json:"lastActiveTime,omitempty"`
+
+	// CurrentMetrics is the observed state of each metric, in the same order as the metrics of the spec.
+	// +kubebuilder:validation:Optional
+	CurrentMetrics []MetricStatus `json:"currentMetrics,omitempty"`
+
+	// ActiveSchedules is the names of the schedules which were active when the scaler was last reconciled.
+	// +kubebuilder:validation:Optional
+	ActiveSchedules []string `json:"activeSchedules,omitempty"`
+
+	// PIDStates is the state of the PID controller of each metric using one.
+	// +kubebuilder:validation:Optional
+	PIDStates []PIDState `json:"pidStates,omitempty"`
+
+	// OutlierStates is the state of the outlier rejection of each metric with valid bounds or a spike filter.
+	// +kubebuilder:validation:Optional
+	OutlierStates []OutlierState `json:"outlierStates,omitempty"`
+
+	// Conditions are the latest observations of the state of the scaler.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
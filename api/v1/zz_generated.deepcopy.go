@@ -5,6 +5,8 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -23,13 +25,30 @@ func (in *Fallback) DeepCopy() *Fallback {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlappingDetection) DeepCopyInto(out *FlappingDetection) {
+	*out = *in
+	out.Window = in.Window
+	out.ScaleDownStabilizationWindow = in.ScaleDownStabilizationWindow
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlappingDetection.
+func (in *FlappingDetection) DeepCopy() *FlappingDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(FlappingDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HorizontalReplicaScaler) DeepCopyInto(out *HorizontalReplicaScaler) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HorizontalReplicaScaler.
@@ -86,8 +105,22 @@ func (in *HorizontalReplicaScalerList) DeepCopyObject() runtime.Object {
 func (in *HorizontalReplicaScalerSpec) DeepCopyInto(out *HorizontalReplicaScalerSpec) {
 	*out = *in
 	out.ScaleTargetRef = in.ScaleTargetRef
+	out.CooldownPeriod = in.CooldownPeriod
 	out.PollingInterval = in.PollingInterval
-	out.ScalingBehavior = in.ScalingBehavior
+	in.ScalingBehavior.DeepCopyInto(&out.ScalingBehavior)
+	out.InitializationPeriod = in.InitializationPeriod
+	if in.Predictive != nil {
+		in, out := &in.Predictive, &out.Predictive
+		*out = new(Predictive)
+		**out = **in
+	}
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]ReplicaSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Fallback != nil {
 		in, out := &in.Fallback, &out.Fallback
 		*out = new(Fallback)
@@ -115,6 +148,56 @@ func (in *HorizontalReplicaScalerSpec) DeepCopy() *HorizontalReplicaScalerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HorizontalReplicaScalerStatus) DeepCopyInto(out *HorizontalReplicaScalerStatus) {
 	*out = *in
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastScaleUpTime != nil {
+		in, out := &in.LastScaleUpTime, &out.LastScaleUpTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ScaleHistory != nil {
+		in, out := &in.ScaleHistory, &out.ScaleHistory
+		*out = make([]ScaleEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastActiveTime != nil {
+		in, out := &in.LastActiveTime, &out.LastActiveTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CurrentMetrics != nil {
+		in, out := &in.CurrentMetrics, &out.CurrentMetrics
+		*out = make([]MetricStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ActiveSchedules != nil {
+		in, out := &in.ActiveSchedules, &out.ActiveSchedules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PIDStates != nil {
+		in, out := &in.PIDStates, &out.PIDStates
+		*out = make([]PIDState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OutlierStates != nil {
+		in, out := &in.OutlierStates, &out.OutlierStates
+		*out = make([]OutlierState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HorizontalReplicaScalerStatus.
@@ -137,7 +220,31 @@ func (in *MetricSpec) DeepCopyInto(out *MetricSpec) {
 			(*out)[key] = val
 		}
 	}
-	out.Target = in.Target
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.SpikeFilter != nil {
+		in, out := &in.SpikeFilter, &out.SpikeFilter
+		*out = new(SpikeFilter)
+		**out = **in
+	}
+	if in.PID != nil {
+		in, out := &in.PID, &out.PID
+		*out = new(PID)
+		**out = **in
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]ScalingStep, len(*in))
+		copy(*out, *in)
+	}
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(TargetSec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSpec.
@@ -150,6 +257,124 @@ func (in *MetricSpec) DeepCopy() *MetricSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricStatus) DeepCopyInto(out *MetricStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricStatus.
+func (in *MetricStatus) DeepCopy() *MetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutlierState) DeepCopyInto(out *OutlierState) {
+	*out = *in
+	if in.RecentValues != nil {
+		in, out := &in.RecentValues, &out.RecentValues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutlierState.
+func (in *OutlierState) DeepCopy() *OutlierState {
+	if in == nil {
+		return nil
+	}
+	out := new(OutlierState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PID) DeepCopyInto(out *PID) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PID.
+func (in *PID) DeepCopy() *PID {
+	if in == nil {
+		return nil
+	}
+	out := new(PID)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PIDState) DeepCopyInto(out *PIDState) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PIDState.
+func (in *PIDState) DeepCopy() *PIDState {
+	if in == nil {
+		return nil
+	}
+	out := new(PIDState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Predictive) DeepCopyInto(out *Predictive) {
+	*out = *in
+	out.Lookahead = in.Lookahead
+	out.Step = in.Step
+	out.Season = in.Season
+	out.History = in.History
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Predictive.
+func (in *Predictive) DeepCopy() *Predictive {
+	if in == nil {
+		return nil
+	}
+	out := new(Predictive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaSchedule) DeepCopyInto(out *ReplicaSchedule) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaSchedule.
+func (in *ReplicaSchedule) DeepCopy() *ReplicaSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScaleEvent) DeepCopyInto(out *ScaleEvent) {
 	*out = *in
@@ -186,6 +411,12 @@ func (in *ScalingBehavior) DeepCopyInto(out *ScalingBehavior) {
 	*out = *in
 	out.ScaleUp = in.ScaleUp
 	out.ScaleDown = in.ScaleDown
+	if in.FlappingDetection != nil {
+		in, out := &in.FlappingDetection, &out.FlappingDetection
+		*out = new(FlappingDetection)
+		**out = **in
+	}
+	out.ScaleDownDelayAfterScaleUp = in.ScaleDownDelayAfterScaleUp
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingBehavior.
@@ -202,6 +433,7 @@ func (in *ScalingBehavior) DeepCopy() *ScalingBehavior {
 func (in *ScalingRules) DeepCopyInto(out *ScalingRules) {
 	*out = *in
 	out.StabilizationWindow = in.StabilizationWindow
+	out.Cooldown = in.Cooldown
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingRules.
@@ -214,6 +446,36 @@ func (in *ScalingRules) DeepCopy() *ScalingRules {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingStep) DeepCopyInto(out *ScalingStep) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingStep.
+func (in *ScalingStep) DeepCopy() *ScalingStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpikeFilter) DeepCopyInto(out *SpikeFilter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpikeFilter.
+func (in *SpikeFilter) DeepCopy() *SpikeFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(SpikeFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetSec) DeepCopyInto(out *TargetSec) {
 	*out = *in
@@ -3,7 +3,9 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -15,7 +17,9 @@ import (
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/scale"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -24,6 +28,10 @@ import (
 	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
 	"github.com/RRethy/horizontalreplicascaler/internal/controller"
 	"github.com/RRethy/horizontalreplicascaler/internal/metric"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/kubeobjectcount"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/otlp"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/resource"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/scalerref"
 	"github.com/RRethy/horizontalreplicascaler/internal/stabilization"
 	// +kubebuilder:scaffold:imports
 )
@@ -46,6 +54,9 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var otlpHTTPAddr string
+	var otlpGRPCAddr string
+	var otlpRetention time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metric endpoint binds to. "+
 		"Use the port :8080. If not set, it will be 0 in order to disable the metrics server")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -56,6 +67,14 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&otlpHTTPAddr, "otlp-http-bind-address", "0", "The address the OTLP/HTTP metrics receiver binds to. "+
+		"Use the port :4318. If not set, it will be 0 in order to disable the OTLP/HTTP receiver. "+
+		"The receiver only runs on the leader, so the manager must run as a single replica")
+	flag.StringVar(&otlpGRPCAddr, "otlp-grpc-bind-address", "0", "The address the OTLP/gRPC metrics receiver binds to. "+
+		"Use the port :4317. If not set, it will be 0 in order to disable the OTLP/gRPC receiver. "+
+		"The receiver only runs on the leader, so the manager must run as a single replica")
+	flag.DurationVar(&otlpRetention, "otlp-retention", 10*time.Minute,
+		"How long metrics received by the OTLP receiver are kept. Must be positive")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -64,6 +83,11 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if otlpRetention <= 0 {
+		setupLog.Error(fmt.Errorf("--otlp-retention must be positive, got %s", otlpRetention), "invalid flags")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -86,6 +110,9 @@ func main() {
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		// Unstructured objects are cached so the kube-object-count metric lists them from informers instead of
+		// listing them from the API server on every reconcile.
+		Client: client.Options{Cache: &client.CacheOptions{Unstructured: true}},
 		Metrics: metricsserver.Options{
 			BindAddress:   metricsAddr,
 			SecureServing: secureMetrics,
@@ -115,14 +142,30 @@ func main() {
 		os.Exit(1)
 	}
 
+	metricOptions := []metric.Option{
+		metric.WithKubeObjectCountClient(kubeobjectcount.NewClient(mgr.GetClient())),
+		metric.WithScalerRefClient(scalerref.NewClient(mgr.GetClient())),
+		metric.WithResourceClient(resource.NewClient(mgr.GetAPIReader())),
+	}
+	if (otlpHTTPAddr != "" && otlpHTTPAddr != "0") || (otlpGRPCAddr != "" && otlpGRPCAddr != "0") {
+		otlpStore := otlp.NewStore(otlpRetention)
+		if err := mgr.Add(otlp.NewReceiver(otlpStore, otlpHTTPAddr, otlpGRPCAddr)); err != nil {
+			setupLog.Error(err, "unable to set up otlp receiver")
+			os.Exit(1)
+		}
+		metricOptions = append(metricOptions, metric.WithOTLPClient(otlp.NewClient(otlpStore)))
+	}
+
 	if err = (&controller.HorizontalReplicaScalerReconciler{
 		Client:                       mgr.GetClient(),
+		APIReader:                    mgr.GetAPIReader(),
 		Scheme:                       mgr.GetScheme(),
 		Recorder:                     mgr.GetEventRecorderFor("horizontalreplicascaler-controller"),
 		ScaleClient:                  scaleClient,
-		MetricClient:                 metric.NewClient(),
+		MetricClient:                 metric.NewClient(metricOptions...),
 		ScaleDownStabilizationWindow: stabilization.NewWindow(stabilization.MaxRollingWindow),
 		ScaleUpStabilizationWindow:   stabilization.NewWindow(stabilization.MinRollingWindow),
+		Clock:                        clock.RealClock{},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HorizontalReplicaScaler")
 		os.Exit(1)
@@ -2,45 +2,157 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
 	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/scale"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+	"github.com/RRethy/horizontalreplicascaler/internal/forecast"
 	"github.com/RRethy/horizontalreplicascaler/internal/metric"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/expression"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/scalerref"
+	"github.com/RRethy/horizontalreplicascaler/internal/outlier"
+	"github.com/RRethy/horizontalreplicascaler/internal/pid"
+	"github.com/RRethy/horizontalreplicascaler/internal/pods"
+	"github.com/RRethy/horizontalreplicascaler/internal/schedule"
 	"github.com/RRethy/horizontalreplicascaler/internal/stabilization"
 )
 
 const (
 	// EventReasonFailedGetScaleSubresource is the reason for the event when the scale subresource cannot be retrieved.
 	EventReasonFailedGetScaleSubresource = "FailedGetScaleSubresource"
+
+	// EventReasonScaledToZero is the reason for the event when the target is scaled to zero since no metric is active.
+	EventReasonScaledToZero = "ScaledToZero"
+	// EventReasonActivated is the reason for the event when the target is woken up from zero since a metric is active.
+	EventReasonActivated = "Activated"
+
+	// EventReasonFlapping is the reason for the event when the replicas of the target start oscillating.
+	EventReasonFlapping = "Flapping"
+
+	// EventReasonOverrideExpired is the reason for the event when the paused and pinned replicas annotations expire.
+	EventReasonOverrideExpired = "OverrideExpired"
+
+	// ConditionReasonPaused is the reason for the Overridden condition when scaling is paused.
+	ConditionReasonPaused = "Paused"
+	// ConditionReasonPinned is the reason for the Overridden condition when the replicas are pinned.
+	ConditionReasonPinned = "Pinned"
+	// ConditionReasonOverrideExpired is the reason for the Overridden condition when the annotations have expired.
+	ConditionReasonOverrideExpired = "OverrideExpired"
+	// ConditionReasonNotOverridden is the reason for the Overridden condition when scaling is driven by the metrics.
+	ConditionReasonNotOverridden = "NotOverridden"
+	// ConditionReasonFlapping is the reason for the Flapping condition when the replicas of the target are oscillating.
+	ConditionReasonFlapping = "Flapping"
+	// ConditionReasonStable is the reason for the Flapping condition when the replicas of the target are not oscillating.
+	ConditionReasonStable = "Stable"
+
+	// MetricReasonWithinTolerance is the reason for a metric recommending the current replicas since its value is within the tolerance of its target.
+	MetricReasonWithinTolerance = "WithinTolerance"
+	// MetricReasonOutlierRejected is the reason for a metric whose value was rejected as an outlier and replaced by
+	// its last accepted value. Without an accepted value, the metric is left out of scaling along with the expressions
+	// referencing it.
+	MetricReasonOutlierRejected = "OutlierRejected"
+	// MetricReasonForecast is the reason for a metric recommending the replicas of its forecast since they are greater than
+	// the replicas of its current value.
+	MetricReasonForecast = "Forecast"
+	// MetricReasonNoPods is the reason for a metric recommending no replicas since its target is relative to the pods of
+	// a target scaled to zero. The metric can still activate the target.
+	MetricReasonNoPods = "NoPods"
+)
+
+// stabilizationSweepInterval is how often the keys of scalers that no longer exist are removed from the stabilization windows.
+const stabilizationSweepInterval = 10 * time.Minute
+
+const (
+	// defaultFlappingWindow is the default window scale events are considered in for flapping detection.
+	defaultFlappingWindow = 10 * time.Minute
+	// defaultMaxReversals is the default number of reversals of the direction of scaling allowed before flapping.
+	defaultMaxReversals = 3
 )
 
+const (
+	// defaultPredictiveStep is the default interval the history of each metric is sampled at for predictive scaling.
+	defaultPredictiveStep = time.Minute
+	// defaultPredictiveSeason is the default season of the holt-winters forecaster.
+	defaultPredictiveSeason = 24 * time.Hour
+	// defaultLinearHistory is the default history kept for the linear forecaster.
+	defaultLinearHistory = time.Hour
+	// maxHistoryBytes bounds the size of the history ConfigMap, leaving room for its metadata within the 1MiB object
+	// limit of the API server.
+	maxHistoryBytes = 900 << 10
+
+	// The smoothing factors of the level, trend and seasonal components of the holt-winters forecaster.
+	holtWintersAlpha = 0.5
+	holtWintersBeta  = 0.1
+	holtWintersGamma = 0.3
+)
+
+// podState is the state of the target's pods needed to convert some metric values into replicas.
+type podState struct {
+	// requests is the sum of the requests of the target's pods for the resource of a utilization target.
+	requests float64
+	// readyPods is the number of ready pods of the target for per-pod pod-average targets.
+	readyPods int32
+	// unreadyPods is the number of unready pods of the target for per-pod pod-average targets.
+	unreadyPods int32
+}
+
 type metricValue struct {
-	metric rrethyv1.MetricSpec
-	value  float64
+	metric   rrethyv1.MetricSpec
+	value    float64
+	replicas int32
+	reason   string
+	// state is the pod state the value was converted into replicas with.
+	state podState
+	// forecast is the forecasted value of the metric when predictive scaling forecasted it.
+	forecast         *float64
+	forecastReplicas int32
+	// excluded is true when the metric has no value to recommend replicas with, so it is left out of scaling.
+	excluded bool
 }
 
 // HorizontalReplicaScalerReconciler reconciles a HorizontalReplicaScaler object
 type HorizontalReplicaScalerReconciler struct {
 	client.Client
+	// APIReader reads directly from the API server, for objects which aren't worth caching.
+	APIReader                    client.Reader
 	Scheme                       *runtime.Scheme
 	Recorder                     record.EventRecorder
 	ScaleClient                  scale.ScalesGetter
 	MetricClient                 metric.Interface
 	ScaleDownStabilizationWindow *stabilization.Window
 	ScaleUpStabilizationWindow   *stabilization.Window
+	Clock                        clock.PassiveClock
 }
 
 // +kubebuilder:rbac:groups=scaling.rrethy.com,resources=horizontalreplicascalers,verbs=get;list;watch;create;update;patch;delete
@@ -48,6 +160,10 @@ type HorizontalReplicaScalerReconciler struct {
 // +kubebuilder:rbac:groups=scaling.rrethy.com,resources=horizontalreplicascalers/finalizers,verbs=update
 // +kubebuilder:rbac:groups="*",resources="*/scale",verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=get;list
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;create;update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -59,7 +175,8 @@ func (r *HorizontalReplicaScalerReconciler) Reconcile(ctx context.Context, horiz
 	log := log.FromContext(ctx)
 
 	if !horizontalReplicaScaler.DeletionTimestamp.IsZero() {
-		// The object is being deleted, don't do anything.
+		// The object is being deleted, only forget its stabilization windows and metrics.
+		r.forgetScaler(horizontalReplicaScaler)
 		return ctrl.Result{}, nil
 	}
 
@@ -78,34 +195,275 @@ func (r *HorizontalReplicaScalerReconciler) Reconcile(ctx context.Context, horiz
 		r.Recorder.Event(horizontalReplicaScaler, corev1.EventTypeWarning, EventReasonFailedGetScaleSubresource, err.Error())
 		return ctrl.Result{RequeueAfter: pollingInterval}, client.IgnoreNotFound(err)
 	}
+	horizontalReplicaScaler.Status.CurrentReplicas = scaleSubresource.Spec.Replicas
 
-	metricResults, err := r.getMetricValues(ctx, horizontalReplicaScaler)
+	overridden, requeueAfter, err := r.applyOverride(ctx, horizontalReplicaScaler, scaleSubresource, pollingInterval)
+	if err != nil {
+		log.Error(err, "applying override")
+		return ctrl.Result{RequeueAfter: pollingInterval}, err
+	}
+	if overridden {
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	metricResults, err := r.getMetricValues(ctx, horizontalReplicaScaler, scaleSubresource)
 	if err != nil {
 		log.Error(err, "getting metric results")
 		return ctrl.Result{RequeueAfter: pollingInterval}, err
 	}
+	if err := r.applyPredictive(ctx, horizontalReplicaScaler, scaleSubresource, metricResults); err != nil {
+		// Predictive scaling only adds to the reactive recommendations, so scaling continues without it.
+		log.Error(err, "applying predictive scaling")
+	}
+	horizontalReplicaScaler.Status.CurrentMetrics = getMetricStatuses(metricResults)
 
-	desiredReplicas := r.getMaxMetricValues(ctx, metricResults)
-	desiredReplicas = r.applyScalingBehavior(ctx, horizontalReplicaScaler, scaleSubresource.Spec.Replicas, desiredReplicas)
-	desiredReplicas = r.applyMinMaxReplicas(ctx, horizontalReplicaScaler, desiredReplicas)
+	desiredReplicas, err := r.aggregateMetricValues(ctx, horizontalReplicaScaler, metricResults)
+	if err != nil {
+		log.Error(err, "aggregating metric results")
+		return ctrl.Result{RequeueAfter: pollingInterval}, err
+	}
+	desiredReplicas, activationApplied, err := r.applyActivation(ctx, horizontalReplicaScaler, metricResults, scaleSubresource.Spec.Replicas, desiredReplicas)
+	if err != nil {
+		log.Error(err, "applying activation")
+		return ctrl.Result{RequeueAfter: pollingInterval}, err
+	}
+	if !activationApplied {
+		r.detectFlapping(ctx, horizontalReplicaScaler)
+		desiredReplicas = r.applyScalingBehavior(ctx, horizontalReplicaScaler, scaleSubresource.Spec.Replicas, desiredReplicas)
+	}
+	desiredReplicas, nextScheduleBoundary, err := r.applyMinMaxReplicas(ctx, horizontalReplicaScaler, desiredReplicas)
+	if err != nil {
+		log.Error(err, "applying min and max replicas")
+		return ctrl.Result{RequeueAfter: pollingInterval}, err
+	}
+	requeueAfter = pollingInterval
+	if !nextScheduleBoundary.IsZero() {
+		// Reconcile as soon as a schedule starts or ends instead of waiting for the next poll.
+		requeueAfter = min(requeueAfter, nextScheduleBoundary.Sub(r.Clock.Now()))
+	}
 
 	err = r.updateScaleSubresource(ctx, horizontalReplicaScaler, scaleSubresource, desiredReplicas)
 	if err != nil {
 		log.Error(err, "updating scale subresource")
-		return ctrl.Result{RequeueAfter: pollingInterval}, nil
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// applyOverride honors the paused and pinned replicas annotations of the scaler and reports them in the Overridden
+// condition. Paused takes precedence over pinned replicas, and the pinned replicas ignore the replica limits.
+// When the annotations took over scaling, overridden is true and the scaler should be reconciled again after requeueAfter.
+// Annotations past their until time are removed from the scaler and scaling continues as usual.
+func (r *HorizontalReplicaScalerReconciler) applyOverride(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, scaleSubresource *autoscalingv1.Scale, pollingInterval time.Duration) (overridden bool, requeueAfter time.Duration, err error) {
+	annotations := horizontalReplicaScaler.Annotations
+	paused := annotations[rrethyv1.PausedAnnotation] == "true"
+	pinnedReplicas, pinned := annotations[rrethyv1.PinnedReplicasAnnotation]
+	if !paused && !pinned {
+		r.setOverriddenCondition(horizontalReplicaScaler, metav1.ConditionFalse, ConditionReasonNotOverridden, "Scaling is driven by the metrics")
+		return false, 0, nil
+	}
+
+	requeueAfter = pollingInterval
+	var untilMessage string
+	if until, ok := annotations[rrethyv1.UntilAnnotation]; ok {
+		untilTime, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return false, 0, fmt.Errorf("failed parsing %s annotation %s: %w", rrethyv1.UntilAnnotation, until, err)
+		}
+		remaining := untilTime.Sub(r.Clock.Now())
+		if remaining <= 0 {
+			return false, 0, r.removeOverride(ctx, horizontalReplicaScaler)
+		}
+		requeueAfter = min(requeueAfter, remaining)
+		untilMessage = " until " + until
+	}
+
+	if paused {
+		horizontalReplicaScaler.Status.DesiredReplicas = scaleSubresource.Spec.Replicas
+		r.setOverriddenCondition(horizontalReplicaScaler, metav1.ConditionTrue, ConditionReasonPaused, "Scaling is paused"+untilMessage)
+		return true, requeueAfter, nil
+	}
+
+	replicas, err := strconv.ParseInt(pinnedReplicas, 10, 32)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed parsing %s annotation %s: %w", rrethyv1.PinnedReplicasAnnotation, pinnedReplicas, err)
+	}
+	if replicas < 0 {
+		return false, 0, fmt.Errorf("%s annotation %s must not be negative", rrethyv1.PinnedReplicasAnnotation, pinnedReplicas)
 	}
+	r.setOverriddenCondition(horizontalReplicaScaler, metav1.ConditionTrue, ConditionReasonPinned, fmt.Sprintf("Replicas are pinned to %d%s", replicas, untilMessage))
+	if err := r.updateScaleSubresource(ctx, horizontalReplicaScaler, scaleSubresource, int32(replicas)); err != nil {
+		return true, requeueAfter, fmt.Errorf("updating scale subresource: %w", err)
+	}
+	return true, requeueAfter, nil
+}
+
+// removeOverride removes the expired paused, pinned replicas and until annotations from the scaler.
+func (r *HorizontalReplicaScalerReconciler) removeOverride(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler) error {
+	// Patching refreshes the scaler from the server, which would discard the status set so far in this reconcile.
+	status := horizontalReplicaScaler.Status.DeepCopy()
+	patch := client.MergeFrom(horizontalReplicaScaler.DeepCopy())
+	delete(horizontalReplicaScaler.Annotations, rrethyv1.PausedAnnotation)
+	delete(horizontalReplicaScaler.Annotations, rrethyv1.PinnedReplicasAnnotation)
+	delete(horizontalReplicaScaler.Annotations, rrethyv1.UntilAnnotation)
+	err := r.Patch(ctx, horizontalReplicaScaler, patch)
+	horizontalReplicaScaler.Status = *status
+	if err != nil {
+		return fmt.Errorf("removing expired override annotations: %w", err)
+	}
+
+	log.FromContext(ctx).Info("override expired")
+	r.Recorder.Event(horizontalReplicaScaler, corev1.EventTypeNormal, EventReasonOverrideExpired, "Override annotations expired and were removed")
+	r.setOverriddenCondition(horizontalReplicaScaler, metav1.ConditionFalse, ConditionReasonOverrideExpired, "Override annotations expired and were removed")
+	return nil
+}
 
-	return ctrl.Result{RequeueAfter: pollingInterval}, nil
+// setOverriddenCondition sets the Overridden condition of the scaler.
+func (r *HorizontalReplicaScalerReconciler) setOverriddenCondition(horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&horizontalReplicaScaler.Status.Conditions, metav1.Condition{
+		Type:               rrethyv1.ConditionTypeOverridden,
+		Status:             status,
+		ObservedGeneration: horizontalReplicaScaler.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// Scalers are also reconciled when the replicas in the status of a scaler they reference with a scaler-ref metric change.
+// The stabilization windows of deleted scalers are forgotten when they are deleted, and periodically swept in case a
+// deletion was missed, e.g. while the controller manager wasn't running.
 func (r *HorizontalReplicaScalerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		ticker := time.NewTicker(stabilizationSweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				if err := r.sweepStabilizationWindows(ctx); err != nil {
+					log.FromContext(ctx).Error(err, "sweeping stabilization windows")
+				}
+			}
+		}
+	}))
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&rrethyv1.HorizontalReplicaScaler{}).
-		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})).
+		For(&rrethyv1.HorizontalReplicaScaler{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(
+			&rrethyv1.HorizontalReplicaScaler{},
+			handler.EnqueueRequestsFromMapFunc(r.findReferencingScalers),
+			builder.WithPredicates(replicasChangedPredicate()),
+		).
+		Watches(
+			&rrethyv1.HorizontalReplicaScaler{},
+			handler.Funcs{
+				DeleteFunc: func(_ context.Context, e event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+					if horizontalReplicaScaler, ok := e.Object.(*rrethyv1.HorizontalReplicaScaler); ok {
+						r.forgetScaler(horizontalReplicaScaler)
+					}
+				},
+			},
+		).
 		Complete(reconcile.AsReconciler(mgr.GetClient(), r))
 }
 
+// stabilizationWindowKey returns the key of the scaler in the stabilization windows.
+func stabilizationWindowKey(horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler) string {
+	return stabilization.KeyFor(
+		horizontalReplicaScaler.Namespace,
+		horizontalReplicaScaler.Name,
+		horizontalReplicaScaler.Spec.ScaleTargetRef.Name,
+		horizontalReplicaScaler.Spec.ScaleTargetRef.Kind,
+		horizontalReplicaScaler.Spec.ScaleTargetRef.Group,
+	)
+}
+
+// forgetScaler removes the state kept in memory for a deleted scaler, which is its keys in the stabilization windows
+// and its series of the controller metrics.
+func (r *HorizontalReplicaScalerReconciler) forgetScaler(horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler) {
+	r.deleteStabilizationWindows(horizontalReplicaScaler)
+	deleteScalerMetrics(horizontalReplicaScaler.Namespace, horizontalReplicaScaler.Name)
+}
+
+// deleteStabilizationWindows removes every key of the scaler from the stabilization windows, including keys of
+// previous scale targets.
+func (r *HorizontalReplicaScalerReconciler) deleteStabilizationWindows(horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler) {
+	prefix := stabilization.KeyFor(horizontalReplicaScaler.Namespace, horizontalReplicaScaler.Name) + "/"
+	isScalerKey := func(key string) bool { return strings.HasPrefix(key, prefix) }
+	r.ScaleDownStabilizationWindow.DeleteFunc(isScalerKey)
+	r.ScaleUpStabilizationWindow.DeleteFunc(isScalerKey)
+	r.recordStabilizationWindowKeys()
+}
+
+// sweepStabilizationWindows removes the keys of scalers which no longer exist or no longer target the same resource
+// from the stabilization windows.
+func (r *HorizontalReplicaScalerReconciler) sweepStabilizationWindows(ctx context.Context) error {
+	var scalers rrethyv1.HorizontalReplicaScalerList
+	if err := r.List(ctx, &scalers); err != nil {
+		return fmt.Errorf("listing scalers: %w", err)
+	}
+
+	keys := make(map[string]bool, len(scalers.Items))
+	for i := range scalers.Items {
+		keys[stabilizationWindowKey(&scalers.Items[i])] = true
+	}
+	isStale := func(key string) bool { return !keys[key] }
+	removed := r.ScaleDownStabilizationWindow.DeleteFunc(isStale) + r.ScaleUpStabilizationWindow.DeleteFunc(isStale)
+	r.recordStabilizationWindowKeys()
+	log.FromContext(ctx).V(1).Info("swept stabilization windows", "removed", removed)
+	return nil
+}
+
+// recordStabilizationWindowKeys updates the metric of the number of keys in the stabilization windows.
+func (r *HorizontalReplicaScalerReconciler) recordStabilizationWindowKeys() {
+	stabilizationWindowKeys.WithLabelValues("down").Set(float64(r.ScaleDownStabilizationWindow.Len()))
+	stabilizationWindowKeys.WithLabelValues("up").Set(float64(r.ScaleUpStabilizationWindow.Len()))
+}
+
+// replicasChangedPredicate returns a predicate which only accepts updates which change the replicas in the status of a scaler.
+func replicasChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldScaler, ok := e.ObjectOld.(*rrethyv1.HorizontalReplicaScaler)
+			if !ok {
+				return false
+			}
+			newScaler, ok := e.ObjectNew.(*rrethyv1.HorizontalReplicaScaler)
+			if !ok {
+				return false
+			}
+			return oldScaler.Status.DesiredReplicas != newScaler.Status.DesiredReplicas ||
+				oldScaler.Status.CurrentReplicas != newScaler.Status.CurrentReplicas
+		},
+	}
+}
+
+// findReferencingScalers returns a request for every scaler in the same namespace with a scaler-ref metric referencing the scaler.
+func (r *HorizontalReplicaScalerReconciler) findReferencingScalers(ctx context.Context, obj client.Object) []reconcile.Request {
+	var scalers rrethyv1.HorizontalReplicaScalerList
+	if err := r.List(ctx, &scalers, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "listing scalers referencing scaler", "scaler", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range scalers.Items {
+		if slices.Contains(scalerref.ReferencedScalers(&scalers.Items[i]), obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&scalers.Items[i])})
+		}
+	}
+	return requests
+}
+
 // getScaleSubresource returns the scale subresource for the target resource.
 func (r *HorizontalReplicaScalerReconciler) getScaleSubresource(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler) (*autoscalingv1.Scale, error) {
 	gr := schema.GroupResource{Group: horizontalReplicaScaler.Spec.ScaleTargetRef.Group, Resource: horizontalReplicaScaler.Spec.ScaleTargetRef.Kind}
@@ -113,73 +471,1178 @@ func (r *HorizontalReplicaScalerReconciler) getScaleSubresource(ctx context.Cont
 }
 
 // getMetricResults returns the result of calculating each metric.
-func (r *HorizontalReplicaScalerReconciler) getMetricValues(_ context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler) ([]metricValue, error) {
-	var values []metricValue
-	for _, metric := range horizontalReplicaScaler.Spec.Metrics {
-		rawValue, err := r.MetricClient.GetValue(metric)
+// Expression metrics are evaluated after every other metric since they depend on the values of named metrics.
+func (r *HorizontalReplicaScalerReconciler) getMetricValues(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, scaleSubresource *autoscalingv1.Scale) ([]metricValue, error) {
+	values := make([]metricValue, len(horizontalReplicaScaler.Spec.Metrics))
+	namedValues := make(map[string]float64)
+	// excludedNames are the names of the metrics left out of scaling, which expressions can't reference.
+	var excludedNames []string
+	for i, metric := range horizontalReplicaScaler.Spec.Metrics {
+		if metric.Type == rrethyv1.ExpressionMetricType {
+			continue
+		}
+		metric, err := r.resolveMetricSecret(ctx, horizontalReplicaScaler.Namespace, metric)
+		if err != nil {
+			return nil, err
+		}
+		if metric.Type == rrethyv1.ResourceMetricType && metric.Config["labelSelector"] == "" {
+			// Resource metrics default to the usage of the target's pods, which utilization targets compare against.
+			metric.Config = maps.Clone(metric.Config)
+			if metric.Config == nil {
+				metric.Config = make(map[string]string, 1)
+			}
+			metric.Config["labelSelector"] = scaleSubresource.Status.Selector
+		}
+		rawValue, err := r.MetricClient.GetValue(ctx, horizontalReplicaScaler.Namespace, metric)
+		if err != nil {
+			return nil, err
+		}
+		if values[i], err = r.newFilteredMetricValue(ctx, horizontalReplicaScaler, scaleSubresource, i, metric, rawValue, namedValues); err != nil {
+			return nil, err
+		}
+		if values[i].excluded && metric.Name != "" {
+			excludedNames = append(excludedNames, metric.Name)
+		}
+	}
+
+	for i, metric := range horizontalReplicaScaler.Spec.Metrics {
+		if metric.Type != rrethyv1.ExpressionMetricType {
+			continue
+		}
+		if len(excludedNames) > 0 {
+			names := slices.Clone(excludedNames)
+			for name := range namedValues {
+				names = append(names, name)
+			}
+			references, err := expression.References(metric.Config["expression"], names)
+			if err != nil {
+				return nil, err
+			}
+			if slices.ContainsFunc(references, func(name string) bool { return slices.Contains(excludedNames, name) }) {
+				values[i] = metricValue{metric: metric, reason: MetricReasonOutlierRejected, excluded: true}
+				if metric.Name != "" {
+					excludedNames = append(excludedNames, metric.Name)
+				}
+				continue
+			}
+		}
+		rawValue, err := expression.Evaluate(metric.Config["expression"], namedValues)
 		if err != nil {
 			return nil, err
 		}
-		values = append(values, metricValue{metric: metric, value: rawValue})
+		if values[i], err = r.newFilteredMetricValue(ctx, horizontalReplicaScaler, scaleSubresource, i, metric, rawValue, namedValues); err != nil {
+			return nil, err
+		}
+		if values[i].excluded && metric.Name != "" {
+			excludedNames = append(excludedNames, metric.Name)
+		}
 	}
+
+	// Drop the state of PID controllers of metrics which no longer use one.
+	horizontalReplicaScaler.Status.PIDStates = slices.DeleteFunc(horizontalReplicaScaler.Status.PIDStates, func(pidState rrethyv1.PIDState) bool {
+		for i, metric := range horizontalReplicaScaler.Spec.Metrics {
+			if metric.Controller == rrethyv1.PIDControllerType && metricKey(i, metric) == pidState.Metric {
+				return false
+			}
+		}
+		return true
+	})
+
+	// Drop the outlier state of metrics which no longer reject outliers.
+	horizontalReplicaScaler.Status.OutlierStates = slices.DeleteFunc(horizontalReplicaScaler.Status.OutlierStates, func(outlierState rrethyv1.OutlierState) bool {
+		for i, metric := range horizontalReplicaScaler.Spec.Metrics {
+			if hasOutlierRejection(metric) && metricKey(i, metric) == outlierState.Metric {
+				return false
+			}
+		}
+		return true
+	})
+
 	return values, nil
 }
 
-func (r *HorizontalReplicaScalerReconciler) getMaxMetricValues(_ context.Context, metricValues []metricValue) int32 {
-	var maxResult float64
-	for _, metricResult := range metricValues {
-		if metricResult.value > maxResult {
-			maxResult = metricResult.value
+// newFilteredMetricValue rejects outliers of the raw value of the metric before converting it into replicas.
+// A metric whose value is rejected before any value was accepted has nothing to use instead, so it is excluded from
+// scaling until it reports a valid value.
+func (r *HorizontalReplicaScalerReconciler) newFilteredMetricValue(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, scaleSubresource *autoscalingv1.Scale, index int, metric rrethyv1.MetricSpec, rawValue float64, namedValues map[string]float64) (metricValue, error) {
+	value, rejected, err := r.rejectOutlier(ctx, horizontalReplicaScaler, index, metric, rawValue)
+	if errors.Is(err, outlier.ErrNoAcceptedValue) {
+		log.FromContext(ctx).Info("excluding metric until it has a valid value", "metric", metricKey(index, metric), "reason", err.Error())
+		return metricValue{metric: metric, value: rawValue, reason: MetricReasonOutlierRejected, excluded: true}, nil
+	}
+	if err != nil {
+		return metricValue{}, err
+	}
+
+	metricValue, err := r.newMetricValue(ctx, horizontalReplicaScaler, scaleSubresource, index, metric, value, namedValues)
+	if err != nil {
+		return metricValue, err
+	}
+	if rejected {
+		metricValue.reason = MetricReasonOutlierRejected
+	}
+	return metricValue, nil
+}
+
+// hasOutlierRejection returns true if the metric has valid bounds or a spike filter.
+func hasOutlierRejection(metric rrethyv1.MetricSpec) bool {
+	return metric.MinValid != "" || metric.MaxValid != "" || metric.SpikeFilter != nil
+}
+
+// rejectOutlier filters the raw value of the metric with its valid bounds and spike filter, updating the outlier state
+// of the metric in the status of the scaler. Rejected values are replaced by the last accepted value of the metric.
+func (r *HorizontalReplicaScalerReconciler) rejectOutlier(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, index int, metric rrethyv1.MetricSpec, rawValue float64) (value float64, rejected bool, err error) {
+	if !hasOutlierRejection(metric) {
+		return rawValue, false, nil
+	}
+
+	filter, err := getOutlierFilter(metric)
+	if err != nil {
+		return 0, false, err
+	}
+
+	key := metricKey(index, metric)
+	var state outlier.State
+	i := slices.IndexFunc(horizontalReplicaScaler.Status.OutlierStates, func(s rrethyv1.OutlierState) bool { return s.Metric == key })
+	if i < 0 {
+		horizontalReplicaScaler.Status.OutlierStates = append(horizontalReplicaScaler.Status.OutlierStates, rrethyv1.OutlierState{Metric: key})
+		i = len(horizontalReplicaScaler.Status.OutlierStates) - 1
+	}
+	outlierState := &horizontalReplicaScaler.Status.OutlierStates[i]
+	state.Suspect = outlierState.Suspect
+	for _, recentValue := range outlierState.RecentValues {
+		parsed, err := strconv.ParseFloat(recentValue, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed parsing recent value %s of %s: %w", recentValue, key, err)
 		}
+		state.Recent = append(state.Recent, parsed)
+	}
+
+	value, reason, state, err := filter.Apply(state, rawValue)
+	if reason != "" {
+		outlierState.Rejections++
+		metricValueRejections.WithLabelValues(horizontalReplicaScaler.Namespace, horizontalReplicaScaler.Name, key, string(reason)).Inc()
+		log.FromContext(ctx).Info("rejected metric value", "metric", key, "value", rawValue, "reason", reason)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("metric %s: %w", key, err)
+	}
+
+	outlierState.Suspect = state.Suspect
+	outlierState.RecentValues = make([]string, 0, len(state.Recent))
+	for _, recentValue := range state.Recent {
+		outlierState.RecentValues = append(outlierState.RecentValues, strconv.FormatFloat(recentValue, 'f', -1, 64))
 	}
-	return int32(maxResult)
+	return value, reason != "", nil
 }
 
-func (r *HorizontalReplicaScalerReconciler) applyMinMaxReplicas(_ context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, desiredReplicas int32) int32 {
-	if desiredReplicas < horizontalReplicaScaler.Spec.MinReplicas {
-		return horizontalReplicaScaler.Spec.MinReplicas
+// getOutlierFilter parses the valid bounds and spike filter of the metric.
+func getOutlierFilter(metric rrethyv1.MetricSpec) (outlier.Filter, error) {
+	filter := outlier.Filter{Samples: 5}
+	if metric.MinValid != "" {
+		minValid, err := strconv.ParseFloat(metric.MinValid, 64)
+		if err != nil {
+			return filter, fmt.Errorf("failed parsing minValid %s: %w", metric.MinValid, err)
+		}
+		filter.MinValid = &minValid
 	}
-	if desiredReplicas > horizontalReplicaScaler.Spec.MaxReplicas {
-		return horizontalReplicaScaler.Spec.MaxReplicas
+	if metric.MaxValid != "" {
+		maxValid, err := strconv.ParseFloat(metric.MaxValid, 64)
+		if err != nil {
+			return filter, fmt.Errorf("failed parsing maxValid %s: %w", metric.MaxValid, err)
+		}
+		filter.MaxValid = &maxValid
 	}
-	return desiredReplicas
+	if metric.SpikeFilter != nil {
+		factor, err := strconv.ParseFloat(metric.SpikeFilter.Factor, 64)
+		if err != nil {
+			return filter, fmt.Errorf("failed parsing spike filter factor %s: %w", metric.SpikeFilter.Factor, err)
+		}
+		if factor <= 1 {
+			return filter, fmt.Errorf("spike filter factor %s must be greater than 1", metric.SpikeFilter.Factor)
+		}
+		filter.SpikeFactor = factor
+		if metric.SpikeFilter.Samples > 0 {
+			filter.Samples = int(metric.SpikeFilter.Samples)
+		}
+	}
+	return filter, nil
 }
 
-func (r *HorizontalReplicaScalerReconciler) applyScalingBehavior(_ context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, currentReplicas, desiredReplicas int32) int32 {
-	stabilizationWindowKey := stabilization.KeyFor(
-		horizontalReplicaScaler.Namespace,
-		horizontalReplicaScaler.Name,
-		horizontalReplicaScaler.Spec.ScaleTargetRef.Name,
-		horizontalReplicaScaler.Spec.ScaleTargetRef.Kind,
-		horizontalReplicaScaler.Spec.ScaleTargetRef.Group,
-	)
+// newMetricValue converts the raw value of the metric into a metricValue and records it in namedValues if the metric is named.
+func (r *HorizontalReplicaScalerReconciler) newMetricValue(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, scaleSubresource *autoscalingv1.Scale, index int, metric rrethyv1.MetricSpec, rawValue float64, namedValues map[string]float64) (metricValue, error) {
+	if metric.Name != "" {
+		if _, ok := namedValues[metric.Name]; ok {
+			return metricValue{}, fmt.Errorf("duplicate metric name %s", metric.Name)
+		}
+		namedValues[metric.Name] = rawValue
+	}
+
+	if scaleSubresource.Spec.Replicas == 0 && isPodTarget(metric.Target) {
+		// Without pods there is nothing to compare the value against, so the metric is only used for activation, which
+		// scales the target up to the MinActiveReplicas before the metric recommends replicas again.
+		return metricValue{metric: metric, value: rawValue, reason: MetricReasonNoPods}, nil
+	}
+
+	state, err := r.getPodState(ctx, horizontalReplicaScaler, scaleSubresource, metric)
+	if err != nil {
+		return metricValue{}, err
+	}
+
+	tolerance, err := getTolerance(horizontalReplicaScaler, metric)
+	if err != nil {
+		return metricValue{}, err
+	}
 
-	stabilizedDownScale, ok := r.ScaleDownStabilizationWindow.Stabilize(stabilizationWindowKey, desiredReplicas, horizontalReplicaScaler.Spec.ScalingBehavior.ScaleDown.StabilizationWindow.Duration)
+	if metric.Controller == rrethyv1.PIDControllerType {
+		replicas, err := r.getPIDReplicas(horizontalReplicaScaler, metricKey(index, metric), metric, rawValue, scaleSubresource.Spec.Replicas, state, tolerance)
+		if err != nil {
+			return metricValue{}, err
+		}
+		return metricValue{metric: metric, value: rawValue, replicas: replicas, state: state}, nil
+	}
+	if metric.Controller == rrethyv1.StepControllerType {
+		replicas, err := getStepReplicas(metric, rawValue, scaleSubresource.Spec.Replicas, state)
+		if err != nil {
+			return metricValue{}, err
+		}
+		return metricValue{metric: metric, value: rawValue, replicas: replicas, state: state}, nil
+	}
+
+	replicas, withinTolerance, err := getReplicasForMetric(metric, rawValue, scaleSubresource.Spec.Replicas, state, tolerance)
+	if err != nil {
+		return metricValue{}, err
+	}
+	value := metricValue{metric: metric, value: rawValue, replicas: replicas, state: state}
+	if withinTolerance {
+		value.reason = MetricReasonWithinTolerance
+	}
+	return value, nil
+}
+
+// getTolerance returns the tolerance of the metric, falling back to the tolerance of the scaler.
+func getTolerance(horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, metric rrethyv1.MetricSpec) (float64, error) {
+	tolerance := horizontalReplicaScaler.Spec.Tolerance
+	if metric.Tolerance != "" {
+		tolerance = metric.Tolerance
+	}
+	if tolerance == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseFloat(tolerance, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing tolerance %s: %w", tolerance, err)
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("tolerance %s must not be negative", tolerance)
+	}
+	return value, nil
+}
+
+// getMetricStatuses returns the status of each metric value.
+func getMetricStatuses(metricValues []metricValue) []rrethyv1.MetricStatus {
+	statuses := make([]rrethyv1.MetricStatus, 0, len(metricValues))
+	for _, metricValue := range metricValues {
+		status := rrethyv1.MetricStatus{
+			Name:     metricValue.metric.Name,
+			Type:     metricValue.metric.Type,
+			Value:    strconv.FormatFloat(metricValue.value, 'f', -1, 64),
+			Replicas: metricValue.replicas,
+			Reason:   metricValue.reason,
+		}
+		if metricValue.forecast != nil {
+			status.Forecast = strconv.FormatFloat(*metricValue.forecast, 'f', -1, 64)
+			status.ForecastReplicas = metricValue.forecastReplicas
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// applyPredictive records the value of each metric in its history and forecasts it at the lookahead of predictive scaling.
+// The forecast replaces the recommendation of the metric when it recommends more replicas, so scaling up happens ahead of
+// demand while scaling down stays reactive. Metrics whose history is too short to forecast keep their recommendation.
+func (r *HorizontalReplicaScalerReconciler) applyPredictive(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, scaleSubresource *autoscalingv1.Scale, metricValues []metricValue) error {
+	predictive := horizontalReplicaScaler.Spec.Predictive
+	if predictive == nil {
+		return nil
+	}
+
+	step := predictive.Step.Duration
+	if step == 0 {
+		step = defaultPredictiveStep
+	}
+	if step < time.Second {
+		return fmt.Errorf("predictive step %s must be at least 1s", step)
+	}
+	season := predictive.Season.Duration
+	if season <= 0 {
+		season = defaultPredictiveSeason
+	}
+	retention := predictive.History.Duration
+	if retention <= 0 {
+		retention = defaultLinearHistory
+		if predictive.Forecaster == rrethyv1.HoltWintersForecasterType {
+			retention = 2 * season
+		}
+	}
+	if retention > forecast.MaxSamples*step {
+		return fmt.Errorf("predictive history %s must be at most %d steps of %s", retention, forecast.MaxSamples, step)
+	}
+	if predictive.Lookahead.Duration <= 0 {
+		return fmt.Errorf("predictive lookahead %s must be positive", predictive.Lookahead.Duration)
+	}
+	horizon := int(math.Ceil(float64(predictive.Lookahead.Duration) / float64(step)))
+
+	// The history is read directly from the API server rather than the cache to avoid caching every ConfigMap in the cluster.
+	configMap := &corev1.ConfigMap{}
+	err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: horizontalReplicaScaler.Namespace, Name: historyConfigMapName(horizontalReplicaScaler)}, configMap)
+	if client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("getting history: %w", err)
+	}
+	create := apierrors.IsNotFound(err)
+	if create {
+		configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: horizontalReplicaScaler.Namespace, Name: historyConfigMapName(horizontalReplicaScaler)}}
+		if err := controllerutil.SetControllerReference(horizontalReplicaScaler, configMap, r.Scheme); err != nil {
+			return err
+		}
+	}
+	data := make(map[string]string, len(metricValues))
+	// The history is only saved when a sample is added in a new step, so it is written at most once per step.
+	changed := create
+
+	now := r.Clock.Now()
+	for i := range metricValues {
+		metricValue := &metricValues[i]
+		switch metricValue.metric.Type {
+		case rrethyv1.StaticMetricType, rrethyv1.CronMetricType, rrethyv1.ScalerRefMetricType:
+			// The value of these metrics is the number of replicas, which isn't worth forecasting.
+			continue
+		}
+
+		key := metricKey(i, metricValue.metric)
+		raw, ok := configMap.Data[key]
+		if metricValue.excluded {
+			// The metric has no value to record, so its history is kept as is.
+			if ok {
+				data[key] = raw
+			}
+			continue
+		}
+		var history forecast.History
+		if ok {
+			if err := json.Unmarshal([]byte(raw), &history); err != nil {
+				log.FromContext(ctx).Error(err, "parsing history, starting a new one", "key", key)
+				history = nil
+			}
+		}
+		if len(history) == 0 || history[len(history)-1].Timestamp != now.Truncate(step).Unix() {
+			history = history.Add(now, metricValue.value, step, retention)
+			encoded, err := json.Marshal(history)
+			if err != nil {
+				return fmt.Errorf("encoding history of %s: %w", key, err)
+			}
+			raw = string(encoded)
+			changed = true
+		}
+		data[key] = raw
+		if metricValue.reason == MetricReasonNoPods {
+			continue
+		}
+
+		var forecasted float64
+		var err error
+		switch predictive.Forecaster {
+		case "", rrethyv1.LinearForecasterType:
+			forecasted, err = forecast.Linear(history.Points(step), horizon)
+		case rrethyv1.HoltWintersForecasterType:
+			forecasted, err = forecast.HoltWinters(history.Points(step), int(season/step), holtWintersAlpha, holtWintersBeta, holtWintersGamma, horizon)
+		default:
+			return fmt.Errorf("unknown forecaster %s", predictive.Forecaster)
+		}
+		if err != nil {
+			log.FromContext(ctx).V(1).Info("not forecasting metric", "key", key, "reason", err.Error())
+			continue
+		}
+		forecasted = max(forecasted, 0)
+
+		forecastReplicas, _, err := getReplicasForMetric(metricValue.metric, forecasted, scaleSubresource.Spec.Replicas, metricValue.state, 0)
+		if err != nil {
+			return err
+		}
+		metricValue.forecast = &forecasted
+		metricValue.forecastReplicas = forecastReplicas
+		if forecastReplicas > metricValue.replicas {
+			metricValue.replicas = forecastReplicas
+			metricValue.reason = MetricReasonForecast
+		}
+	}
+
+	if !changed && len(data) == len(configMap.Data) {
+		return nil
+	}
+	size := 0
+	for key, raw := range data {
+		size += len(key) + len(raw)
+	}
+	if size > maxHistoryBytes {
+		return fmt.Errorf("history of %d bytes is larger than %d bytes, shorten the predictive history or lengthen its step", size, maxHistoryBytes)
+	}
+	configMap.Data = data
+	if create {
+		err = r.Create(ctx, configMap)
+	} else {
+		err = r.Update(ctx, configMap)
+	}
+	if err != nil {
+		return fmt.Errorf("saving history: %w", err)
+	}
+	return nil
+}
+
+// historyConfigMapName returns the name of the ConfigMap holding the history of the metrics of the scaler.
+func historyConfigMapName(horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler) string {
+	return horizontalReplicaScaler.Name + "-history"
+}
+
+// metricKey returns the key identifying the metric in the history ConfigMap and the PID states of the scaler,
+// which is its name if it has one.
+func metricKey(index int, metric rrethyv1.MetricSpec) string {
+	if metric.Name != "" {
+		return metric.Name
+	}
+	return "metric-" + strconv.Itoa(index)
+}
+
+// resolveMetricSecret returns a copy of the metric with the data of its SecretRef merged into the Config.
+func (r *HorizontalReplicaScalerReconciler) resolveMetricSecret(ctx context.Context, namespace string, metric rrethyv1.MetricSpec) (rrethyv1.MetricSpec, error) {
+	if metric.SecretRef == nil {
+		return metric, nil
+	}
+
+	// The secret is read directly from the API server so the controller doesn't cache, or need to list and watch,
+	// every secret in the cluster.
+	var secret corev1.Secret
+	err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: metric.SecretRef.Name}, &secret)
+	if err != nil {
+		return metric, fmt.Errorf("getting secret %s for %s metric: %w", metric.SecretRef.Name, metric.Type, err)
+	}
+
+	config := maps.Clone(metric.Config)
+	if config == nil {
+		config = make(map[string]string, len(secret.Data))
+	}
+	for key, value := range secret.Data {
+		config[key] = string(value)
+	}
+	metric.Config = config
+	return metric, nil
+}
+
+// getPods returns the active pods of the target using the selector of its scale subresource.
+func (r *HorizontalReplicaScalerReconciler) getPods(ctx context.Context, namespace string, scaleSubresource *autoscalingv1.Scale) ([]corev1.Pod, error) {
+	if scaleSubresource.Status.Selector == "" {
+		return nil, fmt.Errorf("scale subresource of %s has no selector", scaleSubresource.Name)
+	}
+	selector, err := labels.Parse(scaleSubresource.Status.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing selector %s: %w", scaleSubresource.Status.Selector, err)
+	}
+
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+
+	activePods := make([]corev1.Pod, 0, len(podList.Items))
+	for _, pod := range podList.Items {
+		if pods.IsActive(&pod) {
+			activePods = append(activePods, pod)
+		}
+	}
+	return activePods, nil
+}
+
+// isPodTarget returns true if the target is relative to the pods of the scale target, which are listed for its pod state.
+func isPodTarget(target *rrethyv1.TargetSec) bool {
+	return target != nil && (target.Type == rrethyv1.UtilizationTargetType || (target.Type == rrethyv1.PodAverageTargetType && target.PerPod))
+}
+
+// getPodState returns the state of the target's pods needed by the target of the metric.
+// The pods are only listed for utilization targets and per-pod pod-average targets.
+func (r *HorizontalReplicaScalerReconciler) getPodState(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, scaleSubresource *autoscalingv1.Scale, metric rrethyv1.MetricSpec) (podState, error) {
+	var state podState
+	switch {
+	case metric.Target == nil:
+		return state, nil
+	case metric.Target.Type == rrethyv1.UtilizationTargetType:
+		if metric.Target.Resource == "" {
+			return state, fmt.Errorf("resource is required for %s targets", metric.Target.Type)
+		}
+		activePods, err := r.getPods(ctx, horizontalReplicaScaler.Namespace, scaleSubresource)
+		if err != nil {
+			return state, err
+		}
+		state.requests = pods.Requests(activePods, metric.Target.Resource, metric.Target.Container)
+	case metric.Target.Type == rrethyv1.PodAverageTargetType && metric.Target.PerPod:
+		activePods, err := r.getPods(ctx, horizontalReplicaScaler.Namespace, scaleSubresource)
+		if err != nil {
+			return state, err
+		}
+		now := r.Clock.Now()
+		for _, pod := range activePods {
+			if pods.IsReady(&pod, now, horizontalReplicaScaler.Spec.InitializationPeriod.Duration) {
+				state.readyPods++
+			} else {
+				state.unreadyPods++
+			}
+		}
+	}
+	return state, nil
+}
+
+// getReplicasForMetric converts the value of a metric into the number of replicas needed to meet its target.
+// The pod state is only used for utilization targets and per-pod pod-average targets.
+// If the ratio of the value to the target is within the tolerance of 1, the current replicas are returned
+// and withinTolerance is true.
+func getReplicasForMetric(metric rrethyv1.MetricSpec, value float64, currentReplicas int32, state podState, tolerance float64) (replicas int32, withinTolerance bool, err error) {
+	switch metric.Type {
+	case rrethyv1.StaticMetricType, rrethyv1.CronMetricType, rrethyv1.ScalerRefMetricType:
+		// The value of these metrics is the number of replicas.
+		return clampReplicas(value), false, nil
+	}
+
+	ratio, replicas, err := getUsageRatio(metric, value, currentReplicas, state)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if currentReplicas > 0 && math.Abs(ratio-1) <= tolerance && replicas != currentReplicas {
+		return currentReplicas, true, nil
+	}
+	return replicas, false, nil
+}
+
+// getUsageRatio returns how far the value of the metric is from its target relative to the current replicas,
+// along with the replicas that would bring it to its target.
+func getUsageRatio(metric rrethyv1.MetricSpec, value float64, currentReplicas int32, state podState) (ratio float64, replicas int32, err error) {
+	if metric.Target == nil {
+		return 0, 0, errors.New("target is required")
+	}
+	target, err := strconv.ParseFloat(metric.Target.Value, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed parsing target value %s: %w", metric.Target.Value, err)
+	}
+	if target <= 0 {
+		return 0, 0, fmt.Errorf("target value %s must be positive", metric.Target.Value)
+	}
+
+	switch metric.Target.Type {
+	case rrethyv1.ValueTargetType:
+		ratio = value / target
+		replicas = clampReplicas(math.Ceil(float64(currentReplicas) * ratio))
+	case rrethyv1.PodAverageTargetType:
+		if metric.Target.PerPod {
+			return getPerPodReplicas(value, target, currentReplicas, state)
+		}
+		ratio = value / (target * float64(currentReplicas))
+		replicas = clampReplicas(math.Ceil(value / target))
+	case rrethyv1.UtilizationTargetType:
+		if state.requests <= 0 {
+			return 0, 0, fmt.Errorf("pods have no %s requests to compute utilization", metric.Target.Resource)
+		}
+		utilization := value / state.requests * 100
+		ratio = utilization / target
+		replicas = clampReplicas(math.Ceil(float64(currentReplicas) * ratio))
+	default:
+		return 0, 0, fmt.Errorf("unknown target type %s", metric.Target.Type)
+	}
+	return ratio, replicas, nil
+}
+
+// getPIDReplicas converts the value of a metric into replicas with its PID controller, updating the state of the
+// controller in the status of the scaler. The error is the usage ratio of the metric minus 1, or 0 within the tolerance,
+// and the output of the controller is the relative change to the current replicas.
+func (r *HorizontalReplicaScalerReconciler) getPIDReplicas(horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, key string, metric rrethyv1.MetricSpec, value float64, currentReplicas int32, state podState, tolerance float64) (int32, error) {
+	switch metric.Type {
+	case rrethyv1.StaticMetricType, rrethyv1.CronMetricType, rrethyv1.ScalerRefMetricType:
+		return 0, fmt.Errorf("controller %s is not supported for %s metrics", metric.Controller, metric.Type)
+	}
+
+	// A target scaled to zero is treated as a single replica so the controller can still scale it up.
+	currentReplicas = max(currentReplicas, 1)
+	ratio, _, err := getUsageRatio(metric, value, currentReplicas, state)
+	if err != nil {
+		return 0, err
+	}
+	pidError := ratio - 1
+	if math.IsNaN(pidError) || math.IsInf(pidError, 0) {
+		return 0, fmt.Errorf("pid error %v of %s is not finite", pidError, key)
+	}
+	if math.Abs(pidError) <= tolerance {
+		pidError = 0
+	}
+
+	gains, err := getPIDGains(metric.PID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := r.Clock.Now()
+	var previous pid.State
+	var dt time.Duration
+	i := slices.IndexFunc(horizontalReplicaScaler.Status.PIDStates, func(s rrethyv1.PIDState) bool { return s.Metric == key })
+	if i >= 0 {
+		pidState := horizontalReplicaScaler.Status.PIDStates[i]
+		if previous, err = parsePIDState(pidState); err != nil {
+			return 0, err
+		}
+		dt = now.Sub(pidState.LastUpdateTime.Time)
+	}
+
+	output, next := gains.Update(previous, pidError, dt)
+	for _, v := range []float64{output, next.Integral, next.Error, next.Derivative} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0, fmt.Errorf("pid state of %s is not finite", key)
+		}
+	}
+	pidState := rrethyv1.PIDState{
+		Metric:         key,
+		Integral:       strconv.FormatFloat(next.Integral, 'f', -1, 64),
+		Error:          strconv.FormatFloat(next.Error, 'f', -1, 64),
+		Derivative:     strconv.FormatFloat(next.Derivative, 'f', -1, 64),
+		LastUpdateTime: metav1.NewTime(now),
+	}
+	if i >= 0 {
+		horizontalReplicaScaler.Status.PIDStates[i] = pidState
+	} else {
+		horizontalReplicaScaler.Status.PIDStates = append(horizontalReplicaScaler.Status.PIDStates, pidState)
+	}
+
+	return clampReplicas(math.Ceil(float64(currentReplicas) * (1 + output))), nil
+}
+
+// getStepReplicas converts the value of a metric into replicas by applying the adjustment of the first of its steps
+// whose bounds contain the usage ratio of the metric minus 1. The current replicas are kept when no step matches.
+func getStepReplicas(metric rrethyv1.MetricSpec, value float64, currentReplicas int32, state podState) (int32, error) {
+	switch metric.Type {
+	case rrethyv1.StaticMetricType, rrethyv1.CronMetricType, rrethyv1.ScalerRefMetricType:
+		return 0, fmt.Errorf("controller %s is not supported for %s metrics", metric.Controller, metric.Type)
+	}
+	if len(metric.Steps) == 0 {
+		return 0, fmt.Errorf("controller %s needs at least one step", metric.Controller)
+	}
+
+	// A target scaled to zero is treated as a single replica so the difference stays finite.
+	ratio, _, err := getUsageRatio(metric, value, max(currentReplicas, 1), state)
+	if err != nil {
+		return 0, err
+	}
+	difference := ratio - 1
+
+	for _, step := range metric.Steps {
+		// An empty bound is unbounded, so the open ends of the steps match any difference.
+		if step.LowerBound != "" {
+			lowerBound, err := strconv.ParseFloat(step.LowerBound, 64)
+			if err != nil {
+				return 0, fmt.Errorf("failed parsing lower bound %s: %w", step.LowerBound, err)
+			}
+			if difference < lowerBound {
+				continue
+			}
+		}
+		if step.UpperBound != "" {
+			upperBound, err := strconv.ParseFloat(step.UpperBound, 64)
+			if err != nil {
+				return 0, fmt.Errorf("failed parsing upper bound %s: %w", step.UpperBound, err)
+			}
+			if difference >= upperBound {
+				continue
+			}
+		}
+		return clampReplicas(float64(currentReplicas) + float64(step.Adjustment)), nil
+	}
+	return currentReplicas, nil
+}
+
+// getPIDGains parses the gains of the PID controller, using the defaults for any that are unset.
+func getPIDGains(spec *rrethyv1.PID) (pid.Gains, error) {
+	gains := pid.Gains{Kp: 1}
+	if spec == nil {
+		return gains, nil
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *float64
+	}{
+		{"kp", spec.Kp, &gains.Kp},
+		{"ki", spec.Ki, &gains.Ki},
+		{"kd", spec.Kd, &gains.Kd},
+		{"integralLimit", spec.IntegralLimit, &gains.IntegralLimit},
+		{"derivativeSmoothing", spec.DerivativeSmoothing, &gains.DerivativeSmoothing},
+	} {
+		if field.value == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			return gains, fmt.Errorf("failed parsing %s %s: %w", field.name, field.value, err)
+		}
+		*field.dest = value
+	}
+	if gains.DerivativeSmoothing < 0 || gains.DerivativeSmoothing >= 1 {
+		return gains, fmt.Errorf("derivativeSmoothing %s must be in [0, 1)", spec.DerivativeSmoothing)
+	}
+	return gains, nil
+}
+
+// parsePIDState parses the state of a PID controller from the status of a scaler. A state with a value that isn't finite
+// is reset.
+func parsePIDState(pidState rrethyv1.PIDState) (pid.State, error) {
+	var state pid.State
+	for _, field := range []struct {
+		value string
+		dest  *float64
+	}{
+		{pidState.Integral, &state.Integral},
+		{pidState.Error, &state.Error},
+		{pidState.Derivative, &state.Derivative},
+	} {
+		if field.value == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			return state, fmt.Errorf("failed parsing pid state of %s: %w", pidState.Metric, err)
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			// A state that isn't finite would keep the controller from ever recovering, so it starts over.
+			return pid.State{}, nil
+		}
+		*field.dest = value
+	}
+	return state, nil
+}
+
+// getPerPodReplicas returns the usage ratio and replicas for a per-pod pod-average target the same way the
+// HorizontalPodAutoscaler handles unready and missing pods.
+// The value is averaged over the ready pods. If that would scale up, unready and missing pods are assumed to report 0,
+// and if that would scale down, missing pods are assumed to be at the target. If accounting for these pods changes the
+// direction of scaling, the current replicas are kept.
+func getPerPodReplicas(value, target float64, currentReplicas int32, state podState) (float64, int32, error) {
+	if state.readyPods == 0 {
+		return 0, 0, errors.New("no ready pods to average the metric over")
+	}
+
+	missingPods := max(currentReplicas-state.readyPods-state.unreadyPods, 0)
+	ratio := value / (target * float64(state.readyPods))
+	var podCount int32
+	switch {
+	case ratio > 1:
+		podCount = state.readyPods + state.unreadyPods + missingPods
+		if adjustedRatio := value / (target * float64(podCount)); adjustedRatio > 1 {
+			ratio = adjustedRatio
+		} else {
+			return 1, currentReplicas, nil
+		}
+	case ratio < 1:
+		podCount = state.readyPods + missingPods
+		if adjustedRatio := (value + target*float64(missingPods)) / (target * float64(podCount)); adjustedRatio < 1 {
+			ratio = adjustedRatio
+		} else {
+			return 1, currentReplicas, nil
+		}
+	default:
+		return 1, currentReplicas, nil
+	}
+	return ratio, clampReplicas(math.Ceil(ratio * float64(podCount))), nil
+}
+
+// clampReplicas converts a number of replicas to an int32, clamping it to [0, math.MaxInt32] since converting
+// a float outside the range of an int32 is undefined. NaN is treated as 0.
+func clampReplicas(replicas float64) int32 {
+	switch {
+	case math.IsNaN(replicas) || replicas <= 0:
+		return 0
+	case replicas >= math.MaxInt32:
+		return math.MaxInt32
+	default:
+		return int32(replicas)
+	}
+}
+
+// aggregateMetricValues combines the recommendations of the metrics that drive scaling with the aggregation of the scaler,
+// then bounds the result by the recommendations of the floor and ceiling metrics.
+func (r *HorizontalReplicaScalerReconciler) aggregateMetricValues(_ context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, metricValues []metricValue) (int32, error) {
+	var replicas []int32
+	var weights []float64
+	var floor, ceiling *int32
+	var excluded bool
+	for _, metricValue := range metricValues {
+		if metricValue.excluded {
+			excluded = true
+			continue
+		}
+		switch getMetricRole(metricValue.metric) {
+		case rrethyv1.FloorMetricRole:
+			if floor == nil || metricValue.replicas > *floor {
+				floor = &metricValue.replicas
+			}
+		case rrethyv1.CeilingMetricRole:
+			if ceiling == nil || metricValue.replicas < *ceiling {
+				ceiling = &metricValue.replicas
+			}
+		default:
+			weight := 1.0
+			if metricValue.metric.Weight != "" {
+				var err error
+				weight, err = strconv.ParseFloat(metricValue.metric.Weight, 64)
+				if err != nil {
+					return 0, fmt.Errorf("failed parsing weight %s: %w", metricValue.metric.Weight, err)
+				}
+			}
+			replicas = append(replicas, metricValue.replicas)
+			weights = append(weights, weight)
+		}
+	}
+
+	var aggregated int32
+	if len(replicas) == 0 && excluded {
+		// Every metric driving scaling is excluded, so there is nothing to scale on and the current replicas are kept.
+		aggregated = horizontalReplicaScaler.Status.CurrentReplicas
+	} else {
+		var err error
+		aggregated, err = aggregate(horizontalReplicaScaler.Spec.Aggregation, replicas, weights)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if floor != nil && aggregated < *floor {
+		aggregated = *floor
+	}
+	if ceiling != nil && aggregated > *ceiling {
+		aggregated = *ceiling
+	}
+	return aggregated, nil
+}
+
+// getMetricRole returns the role of the metric. Cron metrics default to the floor role since their windows set a
+// minimum, and the 0 they recommend outside of them would otherwise lower the min, average, median and weighted
+// aggregations.
+func getMetricRole(metric rrethyv1.MetricSpec) rrethyv1.MetricRole {
+	if metric.Role == "" && metric.Type == rrethyv1.CronMetricType {
+		return rrethyv1.FloorMetricRole
+	}
+	return metric.Role
+}
+
+// aggregate combines the replicas with the aggregation, rounding up. No replicas aggregate to 0.
+func aggregate(aggregation rrethyv1.AggregationType, replicas []int32, weights []float64) (int32, error) {
+	if len(replicas) == 0 {
+		return 0, nil
+	}
+
+	switch aggregation {
+	case "", rrethyv1.MaxAggregationType:
+		return slices.Max(replicas), nil
+	case rrethyv1.MinAggregationType:
+		return slices.Min(replicas), nil
+	case rrethyv1.AverageAggregationType:
+		var sum float64
+		for _, r := range replicas {
+			sum += float64(r)
+		}
+		return clampReplicas(math.Ceil(sum / float64(len(replicas)))), nil
+	case rrethyv1.MedianAggregationType:
+		sorted := slices.Clone(replicas)
+		slices.Sort(sorted)
+		middle := len(sorted) / 2
+		if len(sorted)%2 == 1 {
+			return sorted[middle], nil
+		}
+		return clampReplicas(math.Ceil((float64(sorted[middle-1]) + float64(sorted[middle])) / 2)), nil
+	case rrethyv1.WeightedAggregationType:
+		var sum, totalWeight float64
+		for i, r := range replicas {
+			sum += float64(r) * weights[i]
+			totalWeight += weights[i]
+		}
+		if totalWeight <= 0 {
+			return 0, errors.New("weights of the metrics must not all be 0")
+		}
+		return clampReplicas(math.Ceil(sum / totalWeight)), nil
+	default:
+		return 0, fmt.Errorf("unknown aggregation %s", aggregation)
+	}
+}
+
+// applyActivation handles scaling to and from zero for scalers with a MinReplicas of 0.
+// The target is woken up from zero to at least MinActiveReplicas as soon as any metric is active, and scaled to zero once
+// no metric has been active for the CooldownPeriod. These bypass the stabilization windows, which is reported by applied
+// being true. Otherwise the desired replicas are raised to MinActiveReplicas and scaling continues as usual.
+func (r *HorizontalReplicaScalerReconciler) applyActivation(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, metricValues []metricValue, currentReplicas, desiredReplicas int32) (replicas int32, applied bool, err error) {
+	if horizontalReplicaScaler.Spec.MinReplicas != 0 {
+		return desiredReplicas, false, nil
+	}
+
+	active, err := isAnyMetricActive(metricValues)
+	if err != nil {
+		return 0, false, err
+	}
+	now := metav1.NewTime(r.Clock.Now())
+	if active || horizontalReplicaScaler.Status.LastActiveTime == nil {
+		// A scaler without a last active time has just started being reconciled, so the cooldown starts now.
+		horizontalReplicaScaler.Status.LastActiveTime = &now
+	}
+
+	minActiveReplicas := max(horizontalReplicaScaler.Spec.MinActiveReplicas, 1)
+	if currentReplicas == 0 {
+		if !active {
+			return 0, true, nil
+		}
+		replicas = max(desiredReplicas, minActiveReplicas)
+		log.FromContext(ctx).Info("activating target from zero", "replicas", replicas)
+		r.Recorder.Eventf(horizontalReplicaScaler, corev1.EventTypeNormal, EventReasonActivated, "Activated from zero to %d replicas", replicas)
+		return replicas, true, nil
+	}
+
+	if !active && now.Sub(horizontalReplicaScaler.Status.LastActiveTime.Time) >= horizontalReplicaScaler.Spec.CooldownPeriod.Duration {
+		log.FromContext(ctx).Info("scaling target to zero")
+		r.Recorder.Event(horizontalReplicaScaler, corev1.EventTypeNormal, EventReasonScaledToZero, "No metric is active, scaled to zero")
+		return 0, true, nil
+	}
+	return max(desiredReplicas, minActiveReplicas), false, nil
+}
+
+// isAnyMetricActive returns true if the value of any metric other than a ceiling is above its activation threshold.
+func isAnyMetricActive(metricValues []metricValue) (bool, error) {
+	for _, metricValue := range metricValues {
+		if getMetricRole(metricValue.metric) == rrethyv1.CeilingMetricRole || metricValue.excluded {
+			continue
+		}
+		var activation float64
+		if metricValue.metric.Activation != "" {
+			var err error
+			activation, err = strconv.ParseFloat(metricValue.metric.Activation, 64)
+			if err != nil {
+				return false, fmt.Errorf("failed parsing activation %s: %w", metricValue.metric.Activation, err)
+			}
+		}
+		if metricValue.value > activation {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// applyMinMaxReplicas bounds the desired replicas by the MinReplicas and MaxReplicas of the scaler, which are overridden
+// by the active schedules. The active schedules are recorded in the status, and the next time any schedule starts or
+// ends is returned so the scaler can be reconciled then, or zero if no schedule changes again.
+func (r *HorizontalReplicaScalerReconciler) applyMinMaxReplicas(_ context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, desiredReplicas int32) (int32, time.Time, error) {
+	minReplicas, maxReplicas := horizontalReplicaScaler.Spec.MinReplicas, horizontalReplicaScaler.Spec.MaxReplicas
+	var scheduledMinReplicas, scheduledMaxReplicas *int32
+	var activeSchedules []string
+	var nextBoundary time.Time
+	now := r.Clock.Now()
+	for _, replicaSchedule := range horizontalReplicaScaler.Spec.Schedules {
+		active, next, err := schedule.Evaluate(replicaSchedule, now)
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		if !next.IsZero() && (nextBoundary.IsZero() || next.Before(nextBoundary)) {
+			nextBoundary = next
+		}
+		if !active {
+			continue
+		}
+
+		activeSchedules = append(activeSchedules, replicaSchedule.Name)
+		if replicaSchedule.MinReplicas != nil && (scheduledMinReplicas == nil || *replicaSchedule.MinReplicas > *scheduledMinReplicas) {
+			scheduledMinReplicas = replicaSchedule.MinReplicas
+		}
+		if replicaSchedule.MaxReplicas != nil && (scheduledMaxReplicas == nil || *replicaSchedule.MaxReplicas > *scheduledMaxReplicas) {
+			scheduledMaxReplicas = replicaSchedule.MaxReplicas
+		}
+	}
+	horizontalReplicaScaler.Status.ActiveSchedules = activeSchedules
+	if scheduledMinReplicas != nil {
+		minReplicas = *scheduledMinReplicas
+	}
+	if scheduledMaxReplicas != nil {
+		maxReplicas = *scheduledMaxReplicas
+	}
+
+	if desiredReplicas < minReplicas {
+		return minReplicas, nextBoundary, nil
+	}
+	if desiredReplicas > maxReplicas {
+		return maxReplicas, nextBoundary, nil
+	}
+	return desiredReplicas, nextBoundary, nil
+}
+
+// detectFlapping reports whether the direction of scaling reversed more than the max reversals of the flapping detection
+// within its window in the Flapping condition, recording an event when the target starts flapping.
+// Scale events outside the window are dropped from the scale history.
+func (r *HorizontalReplicaScalerReconciler) detectFlapping(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler) {
+	flappingDetection := horizontalReplicaScaler.Spec.ScalingBehavior.FlappingDetection
+	if flappingDetection == nil {
+		horizontalReplicaScaler.Status.ScaleHistory = nil
+		meta.RemoveStatusCondition(&horizontalReplicaScaler.Status.Conditions, rrethyv1.ConditionTypeFlapping)
+		return
+	}
+
+	cutoff := r.Clock.Now().Add(-getFlappingWindow(flappingDetection, false))
+	horizontalReplicaScaler.Status.ScaleHistory = slices.DeleteFunc(horizontalReplicaScaler.Status.ScaleHistory, func(scaleEvent rrethyv1.ScaleEvent) bool {
+		return scaleEvent.Timestamp.Time.Before(cutoff)
+	})
+
+	reversals := countReversals(horizontalReplicaScaler.Status.ScaleHistory)
+	maxReversals := flappingDetection.MaxReversals
+	if maxReversals == 0 {
+		maxReversals = defaultMaxReversals
+	}
+	if reversals <= maxReversals {
+		meta.SetStatusCondition(&horizontalReplicaScaler.Status.Conditions, metav1.Condition{
+			Type:               rrethyv1.ConditionTypeFlapping,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: horizontalReplicaScaler.Generation,
+			Reason:             ConditionReasonStable,
+			Message:            fmt.Sprintf("Scaling reversed direction %d times within the window", reversals),
+		})
+		return
+	}
+
+	message := fmt.Sprintf("Scaling reversed direction %d times within the window, extending the scale down stabilization window", reversals)
+	if !meta.IsStatusConditionTrue(horizontalReplicaScaler.Status.Conditions, rrethyv1.ConditionTypeFlapping) {
+		log.FromContext(ctx).Info("target is flapping", "reversals", reversals)
+		r.Recorder.Event(horizontalReplicaScaler, corev1.EventTypeWarning, EventReasonFlapping, message)
+	}
+	meta.SetStatusCondition(&horizontalReplicaScaler.Status.Conditions, metav1.Condition{
+		Type:               rrethyv1.ConditionTypeFlapping,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: horizontalReplicaScaler.Generation,
+		Reason:             ConditionReasonFlapping,
+		Message:            message,
+	})
+}
+
+// getFlappingWindow returns the window of the flapping detection, or its scale down stabilization window if damping is true.
+// The scale down stabilization window defaults to the window.
+func getFlappingWindow(flappingDetection *rrethyv1.FlappingDetection, damping bool) time.Duration {
+	if damping && flappingDetection.ScaleDownStabilizationWindow.Duration > 0 {
+		return flappingDetection.ScaleDownStabilizationWindow.Duration
+	}
+	if flappingDetection.Window.Duration > 0 {
+		return flappingDetection.Window.Duration
+	}
+	return defaultFlappingWindow
+}
+
+// countReversals returns the number of times the direction of scaling reversed between consecutive scale events.
+func countReversals(scaleHistory []rrethyv1.ScaleEvent) int32 {
+	var reversals int32
+	var previousDirection int32
+	for i := 1; i < len(scaleHistory); i++ {
+		direction := scaleHistory[i].Value - scaleHistory[i-1].Value
+		if direction == 0 {
+			continue
+		}
+		if previousDirection != 0 && (direction > 0) != (previousDirection > 0) {
+			reversals++
+		}
+		previousDirection = direction
+	}
+	return reversals
+}
+
+// applyScalingBehavior stabilizes the desired replicas with the stabilization windows of the scaler, then keeps the
+// current replicas while scaling in that direction is cooling down since the last scale event, or while scaling down
+// is delayed after the last scale up. While the target is flapping, the scale down stabilization window is extended.
+func (r *HorizontalReplicaScalerReconciler) applyScalingBehavior(_ context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, currentReplicas, desiredReplicas int32) int32 {
+	key := stabilizationWindowKey(horizontalReplicaScaler)
+	behavior := horizontalReplicaScaler.Spec.ScalingBehavior
+	scaleDownStabilizationWindow := behavior.ScaleDown.StabilizationWindow.Duration
+	if behavior.FlappingDetection != nil && meta.IsStatusConditionTrue(horizontalReplicaScaler.Status.Conditions, rrethyv1.ConditionTypeFlapping) {
+		scaleDownStabilizationWindow = max(scaleDownStabilizationWindow, getFlappingWindow(behavior.FlappingDetection, true))
+	}
+	stabilizedDownScale, ok := r.ScaleDownStabilizationWindow.StabilizeWith(key, desiredReplicas, scaleDownStabilizationWindow, getStatistic(behavior.ScaleDown, r.ScaleDownStabilizationWindow.Type))
 	if !ok {
 		stabilizedDownScale = currentReplicas
 	}
 
-	stabilizedUpScale, ok := r.ScaleUpStabilizationWindow.Stabilize(stabilizationWindowKey, desiredReplicas, horizontalReplicaScaler.Spec.ScalingBehavior.ScaleUp.StabilizationWindow.Duration)
+	stabilizedUpScale, ok := r.ScaleUpStabilizationWindow.StabilizeWith(key, desiredReplicas, behavior.ScaleUp.StabilizationWindow.Duration, getStatistic(behavior.ScaleUp, r.ScaleUpStabilizationWindow.Type))
 	if !ok {
 		stabilizedUpScale = currentReplicas
 	}
+	r.recordStabilizationWindowKeys()
 
+	status := horizontalReplicaScaler.Status
+	now := r.Clock.Now()
 	if desiredReplicas < currentReplicas {
+		if isWithin(now, status.LastScaleTime, behavior.ScaleDown.Cooldown.Duration) || isWithin(now, status.LastScaleUpTime, behavior.ScaleDownDelayAfterScaleUp.Duration) {
+			return currentReplicas
+		}
 		return slices.Min([]int32{stabilizedDownScale, currentReplicas})
 	} else if desiredReplicas > currentReplicas {
+		if isWithin(now, status.LastScaleTime, behavior.ScaleUp.Cooldown.Duration) {
+			return currentReplicas
+		}
 		return slices.Max([]int32{stabilizedUpScale, currentReplicas})
 	}
 	return currentReplicas
 }
 
+// getStatistic returns the statistic of the stabilization window of the scaling rules, which defaults to the type of the window.
+func getStatistic(rules rrethyv1.ScalingRules, defaultType stabilization.RollingWindowType) stabilization.Statistic {
+	switch rules.StabilizationType {
+	case rrethyv1.MaxStabilizationType:
+		return stabilization.Statistic{Type: stabilization.MaxRollingWindow}
+	case rrethyv1.MinStabilizationType:
+		return stabilization.Statistic{Type: stabilization.MinRollingWindow}
+	case rrethyv1.PercentileStabilizationType:
+		percentile := rules.StabilizationPercentile
+		if percentile == 0 {
+			percentile = 90
+		}
+		return stabilization.Statistic{Type: stabilization.PercentileRollingWindow, Percentile: percentile}
+	case rrethyv1.EWMAStabilizationType:
+		return stabilization.Statistic{Type: stabilization.EWMARollingWindow}
+	default:
+		return stabilization.Statistic{Type: defaultType}
+	}
+}
+
+// isWithin returns true if now is less than duration after since.
+func isWithin(now time.Time, since *metav1.Time, duration time.Duration) bool {
+	return since != nil && now.Sub(since.Time) < duration
+}
+
 func (r *HorizontalReplicaScalerReconciler) updateScaleSubresource(ctx context.Context, horizontalReplicaScaler *rrethyv1.HorizontalReplicaScaler, scaleSubresource *autoscalingv1.Scale, desiredReplicas int32) error {
 	var err error
-	if horizontalReplicaScaler.Spec.DryRun {
-		horizontalReplicaScaler.Status.DesiredReplicas = desiredReplicas
-	} else {
+	horizontalReplicaScaler.Status.DesiredReplicas = desiredReplicas
+	if !horizontalReplicaScaler.Spec.DryRun {
+		currentReplicas := scaleSubresource.Spec.Replicas
 		scaleSubresource.Spec.Replicas = desiredReplicas
 		gr := schema.GroupResource{Group: horizontalReplicaScaler.Spec.ScaleTargetRef.Group, Resource: horizontalReplicaScaler.Spec.ScaleTargetRef.Kind}
 		_, err = r.ScaleClient.Scales(horizontalReplicaScaler.Namespace).Update(ctx, gr, scaleSubresource, metav1.UpdateOptions{})
+		if err == nil && desiredReplicas != currentReplicas {
+			now := metav1.NewTime(r.Clock.Now())
+			horizontalReplicaScaler.Status.LastScaleTime = &now
+			if desiredReplicas > currentReplicas {
+				horizontalReplicaScaler.Status.LastScaleUpTime = &now
+			}
+			if horizontalReplicaScaler.Spec.ScalingBehavior.FlappingDetection != nil {
+				if len(horizontalReplicaScaler.Status.ScaleHistory) == 0 {
+					// Record the replicas before the first scale event so its direction is known.
+					horizontalReplicaScaler.Status.ScaleHistory = append(horizontalReplicaScaler.Status.ScaleHistory, rrethyv1.ScaleEvent{Value: currentReplicas, Timestamp: now})
+				}
+				horizontalReplicaScaler.Status.ScaleHistory = append(horizontalReplicaScaler.Status.ScaleHistory, rrethyv1.ScaleEvent{Value: desiredReplicas, Timestamp: now})
+			}
+		}
 	}
 	return err
 }
@@ -11,6 +11,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -53,7 +54,7 @@ var (
 			ScaleTargetRef: rrethyv1.ScaleTargetRef{Group: "apps", Kind: "Deployment", Name: deploymentName},
 			MinReplicas:    initialMinReplicas,
 			MaxReplicas:    initialMaxReplicas,
-			Metrics:        []rrethyv1.MetricSpec{{Type: "static", Target: rrethyv1.TargetSec{Type: "value", Value: fmt.Sprintf("%d", initialDeploymentScale)}}},
+			Metrics:        []rrethyv1.MetricSpec{{Type: "static", Target: &rrethyv1.TargetSec{Type: "value", Value: fmt.Sprintf("%d", initialDeploymentScale)}}},
 		},
 	}
 )
@@ -128,8 +129,8 @@ var _ = Describe("HorizontalReplicaScaler Controller", func() {
 
 			By("Adding a new metric to the scaler")
 			horizontalreplicascaler.Spec.Metrics = []rrethyv1.MetricSpec{
-				{Type: "static", Target: rrethyv1.TargetSec{Type: "value", Value: "9"}},
-				{Type: "static", Target: rrethyv1.TargetSec{Type: "value", Value: "7"}},
+				{Type: "static", Target: &rrethyv1.TargetSec{Type: "value", Value: "9"}},
+				{Type: "static", Target: &rrethyv1.TargetSec{Type: "value", Value: "7"}},
 			}
 			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
 
@@ -141,6 +142,375 @@ var _ = Describe("HorizontalReplicaScaler Controller", func() {
 			}, eventuallyTimeout, interval).Should(Equal(int32(9)))
 		})
 
+		It("Should evaluate expression metrics over named metrics", func() {
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+
+			By("Adding an expression metric over two named metrics")
+			horizontalreplicascaler.Spec.Metrics = []rrethyv1.MetricSpec{
+				{Name: "requests", Type: "static", Target: &rrethyv1.TargetSec{Type: "value", Value: "6"}},
+				{Name: "latency_budget", Type: "static", Target: &rrethyv1.TargetSec{Type: "value", Value: "2"}},
+				{Type: "expression", Config: map[string]string{"expression": "requests + latency_budget"}, Target: &rrethyv1.TargetSec{Type: "pod-average", Value: "0.5"}},
+			}
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the deployment to check the replica count")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(16)))
+		})
+
+		It("Should keep the current replicas when a metric is within tolerance", func() {
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+
+			By("Adding a metric 5% above its target with a 10% tolerance")
+			horizontalreplicascaler.Spec.Tolerance = "0.1"
+			horizontalreplicascaler.Spec.Metrics = []rrethyv1.MetricSpec{
+				{Name: "load", Type: "static", Target: &rrethyv1.TargetSec{Type: "value", Value: "1"}},
+				{Type: "expression", Config: map[string]string{"expression": "load * 10.5"}, Target: &rrethyv1.TargetSec{Type: "value", Value: "10"}},
+			}
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the status of the scaler to check the reason of the metric")
+			Eventually(func() []rrethyv1.MetricStatus {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				return horizontalreplicascaler.Status.CurrentMetrics
+			}, eventuallyTimeout, interval).Should(ContainElement(rrethyv1.MetricStatus{Type: "expression", Value: "10.5", Replicas: initialDeploymentScale, Reason: MetricReasonWithinTolerance}))
+
+			By("Getting the deployment to check the replica count")
+			Consistently(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, consistentlyTimeout, interval).Should(Equal(int32(initialDeploymentScale)))
+		})
+
+		It("Should aggregate the metrics and respect floor and ceiling metrics", func() {
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+
+			By("Using the median of three metrics with a floor above it and a ceiling above the floor")
+			horizontalreplicascaler.Spec.Aggregation = "median"
+			horizontalreplicascaler.Spec.Metrics = []rrethyv1.MetricSpec{
+				{Type: "static", Target: &rrethyv1.TargetSec{Type: "value", Value: "4"}},
+				{Type: "static", Target: &rrethyv1.TargetSec{Type: "value", Value: "8"}},
+				{Type: "static", Target: &rrethyv1.TargetSec{Type: "value", Value: "14"}},
+				{Type: "static", Role: "floor", Target: &rrethyv1.TargetSec{Type: "value", Value: "9"}},
+				{Type: "static", Role: "ceiling", Target: &rrethyv1.TargetSec{Type: "value", Value: "12"}},
+			}
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the deployment to check the replica count")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(9)))
+		})
+
+		It("Should scale to zero when no metric is active and wake up when one is", func() {
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+
+			By("Allowing scale to zero with a metric below its activation threshold")
+			horizontalreplicascaler.Spec.MinReplicas = 0
+			horizontalreplicascaler.Spec.MinActiveReplicas = 4
+			horizontalreplicascaler.Spec.Metrics = []rrethyv1.MetricSpec{
+				{Type: "static", Activation: "2", Target: &rrethyv1.TargetSec{Type: "value", Value: "2"}},
+			}
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the deployment to check it was scaled to zero")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(0)))
+
+			By("Raising the metric above its activation threshold")
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+			horizontalreplicascaler.Spec.Metrics[0].Target.Value = "3"
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the deployment to check it was woken up to the min active replicas")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(4)))
+		})
+
+		It("Should wake up from zero with a utilization metric", func() {
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+
+			By("Allowing scale to zero with a metric below its activation threshold")
+			horizontalreplicascaler.Spec.MinReplicas = 0
+			horizontalreplicascaler.Spec.MinActiveReplicas = 4
+			horizontalreplicascaler.Spec.Metrics = []rrethyv1.MetricSpec{
+				{Type: "static", Activation: "2", Target: &rrethyv1.TargetSec{Type: "value", Value: "2"}},
+			}
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the deployment to check it was scaled to zero")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(0)))
+
+			By("Replacing the metric with an active utilization metric, which has no pods to compare against")
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+			horizontalreplicascaler.Spec.Metrics = []rrethyv1.MetricSpec{
+				{Name: "load", Type: "static", Role: "ceiling", Target: &rrethyv1.TargetSec{Type: "value", Value: "20"}},
+				{Type: "expression", Config: map[string]string{"expression": "load * 10"}, Target: &rrethyv1.TargetSec{Type: "utilization", Value: "50", Resource: corev1.ResourceCPU}},
+			}
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the deployment to check it was woken up to the min active replicas")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(4)))
+		})
+
+		It("Should scale up ahead of a rising metric with predictive scaling", func() {
+			By("Creating a history of the metric rising by 2 every minute")
+			now := fakeclock.Now().Truncate(time.Minute)
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: scalerName + "-history", Namespace: namespace},
+				Data: map[string]string{
+					"requests": fmt.Sprintf(`[{"t":%d,"v":4},{"t":%d,"v":6},{"t":%d,"v":8}]`,
+						now.Add(-3*time.Minute).Unix(), now.Add(-2*time.Minute).Unix(), now.Add(-time.Minute).Unix()),
+				},
+			}
+			Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, configMap)).To(Succeed())
+			})
+
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+
+			By("Forecasting the metric 2 minutes ahead")
+			horizontalreplicascaler.Spec.Predictive = &rrethyv1.Predictive{Forecaster: "linear", Lookahead: metav1.Duration{Duration: 2 * time.Minute}}
+			horizontalreplicascaler.Spec.Metrics = []rrethyv1.MetricSpec{
+				{Name: "load", Type: "static", Role: "ceiling", Target: &rrethyv1.TargetSec{Type: "value", Value: "20"}},
+				{Name: "requests", Type: "expression", Config: map[string]string{"expression": "load / 2"}, Target: &rrethyv1.TargetSec{Type: "pod-average", Value: "1"}},
+			}
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the status of the scaler to check the forecast of the metric")
+			Eventually(func() []rrethyv1.MetricStatus {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				return horizontalreplicascaler.Status.CurrentMetrics
+			}, eventuallyTimeout, interval).Should(ContainElement(rrethyv1.MetricStatus{Name: "requests", Type: "expression", Value: "10", Replicas: 14, Forecast: "14", ForecastReplicas: 14, Reason: MetricReasonForecast}))
+
+			By("Getting the deployment to check the replica count")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(14)))
+		})
+
+		It("Should scale with the PID controller of a metric", func() {
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+
+			By("Adding a metric 50% above its target with a PID controller with a Kp of 0.5")
+			horizontalreplicascaler.Spec.Metrics = []rrethyv1.MetricSpec{
+				{Name: "load", Type: "static", Role: "ceiling", Target: &rrethyv1.TargetSec{Type: "value", Value: "20"}},
+				{Type: "expression", Config: map[string]string{"expression": "load * 0.75"}, Controller: "pid", PID: &rrethyv1.PID{Kp: "0.5"}, Target: &rrethyv1.TargetSec{Type: "value", Value: "10"}},
+			}
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the deployment to check the replica count")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(13)))
+
+			By("Getting the status of the scaler to check the state of the PID controller")
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+			Expect(horizontalreplicascaler.Status.PIDStates).To(HaveLen(1))
+			Expect(horizontalreplicascaler.Status.PIDStates[0].Metric).To(Equal("metric-1"))
+			Expect(horizontalreplicascaler.Status.PIDStates[0].Error).To(Equal("0.5"))
+		})
+
+		It("Should scale by the step matching a metric", func() {
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+
+			By("Adding a metric 30% above its target with steps adding 2 replicas from 20% and 5 replicas from 40% above it")
+			horizontalreplicascaler.Spec.Metrics = []rrethyv1.MetricSpec{
+				{Name: "load", Type: "static", Role: "ceiling", Target: &rrethyv1.TargetSec{Type: "value", Value: "20"}},
+				{
+					Type:       "expression",
+					Config:     map[string]string{"expression": "load * 0.65"},
+					Controller: "step",
+					Steps: []rrethyv1.ScalingStep{
+						{LowerBound: "0.2", UpperBound: "0.4", Adjustment: 2},
+						{LowerBound: "0.4", Adjustment: 5},
+					},
+					Target: &rrethyv1.TargetSec{Type: "value", Value: "10"},
+				},
+			}
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the deployment to check the replica count")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(initialDeploymentScale + 2)))
+		})
+
+		It("Should pin the replicas with an annotation", func() {
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+
+			By("Pinning the replicas above the max replicas")
+			horizontalreplicascaler.Annotations = map[string]string{rrethyv1.PinnedReplicasAnnotation: "25"}
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the deployment to check the replica count")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(25)))
+
+			By("Getting the status of the scaler to check the condition")
+			Eventually(func() string {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				condition := meta.FindStatusCondition(horizontalreplicascaler.Status.Conditions, rrethyv1.ConditionTypeOverridden)
+				if condition == nil {
+					return ""
+				}
+				return condition.Reason
+			}, eventuallyTimeout, interval).Should(Equal(ConditionReasonPinned))
+		})
+
+		It("Should remove expired override annotations", func() {
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+
+			By("Pausing the scaler until a time in the past and changing the static value")
+			horizontalreplicascaler.Annotations = map[string]string{
+				rrethyv1.PausedAnnotation: "true",
+				rrethyv1.UntilAnnotation:  fakeclock.Now().Add(-time.Minute).Format(time.RFC3339),
+			}
+			horizontalreplicascaler.Spec.Metrics[0].Target.Value = "5"
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the scaler to check the annotations were removed")
+			Eventually(func() map[string]string {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				return horizontalreplicascaler.Annotations
+			}, eventuallyTimeout, interval).ShouldNot(HaveKey(rrethyv1.PausedAnnotation))
+
+			By("Getting the deployment to check the replica count")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(5)))
+		})
+
+		It("Should reject metric values above the max valid value", func() {
+			By("Adding a max valid value to the metric")
+			Eventually(func() error {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				horizontalreplicascaler.Spec.Metrics[0].MaxValid = "15"
+				return k8sClient.Update(ctx, &horizontalreplicascaler)
+			}, eventuallyTimeout, interval).Should(Succeed())
+
+			By("Waiting for the value to be accepted")
+			Eventually(func() []rrethyv1.OutlierState {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				return horizontalreplicascaler.Status.OutlierStates
+			}, eventuallyTimeout, interval).Should(Equal([]rrethyv1.OutlierState{{Metric: "metric-0", RecentValues: []string{"10"}}}))
+
+			By("Changing the static value above the max valid value")
+			Eventually(func() error {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				horizontalreplicascaler.Spec.Metrics[0].Target.Value = "1000"
+				return k8sClient.Update(ctx, &horizontalreplicascaler)
+			}, eventuallyTimeout, interval).Should(Succeed())
+
+			By("Getting the status of the scaler to check the rejection")
+			Eventually(func() int32 {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				if len(horizontalreplicascaler.Status.OutlierStates) == 0 {
+					return 0
+				}
+				return horizontalreplicascaler.Status.OutlierStates[0].Rejections
+			}, eventuallyTimeout, interval).Should(Equal(int32(1)))
+
+			By("Getting the deployment to check the replica count")
+			Consistently(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, consistentlyTimeout, interval).Should(Equal(int32(initialDeploymentScale)))
+		})
+
+		It("Should leave out a metric whose first value is out of bounds", func() {
+			By("Adding a metric whose first value is above its max valid value, and an expression referencing it")
+			Eventually(func() error {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				horizontalreplicascaler.Spec.Metrics = []rrethyv1.MetricSpec{
+					{Type: "static", Target: &rrethyv1.TargetSec{Type: "value", Value: "12"}},
+					{Name: "load", Type: "static", MaxValid: "15", Target: &rrethyv1.TargetSec{Type: "value", Value: "1000"}},
+					{Type: "expression", Config: map[string]string{"expression": "load * 2.0"}, Target: &rrethyv1.TargetSec{Type: "pod-average", Value: "1"}},
+				}
+				return k8sClient.Update(ctx, &horizontalreplicascaler)
+			}, eventuallyTimeout, interval).Should(Succeed())
+
+			By("Getting the deployment to check only the valid metric is scaled on")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(int32(12)))
+
+			By("Getting the status of the scaler to check the metric and expression were rejected")
+			Eventually(func() []string {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				var reasons []string
+				for _, status := range horizontalreplicascaler.Status.CurrentMetrics {
+					reasons = append(reasons, status.Reason)
+				}
+				return reasons
+			}, eventuallyTimeout, interval).Should(Equal([]string{"", MetricReasonOutlierRejected, MetricReasonOutlierRejected}))
+		})
+
 		It("Should respect min replicas", func() {
 			By("Getting the existing scaler")
 			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
@@ -159,6 +529,32 @@ var _ = Describe("HorizontalReplicaScaler Controller", func() {
 			}, eventuallyTimeout, interval).Should(Equal(int32(5)))
 		})
 
+		It("Should respect the min replicas of an active schedule", func() {
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+
+			By("Adding an active schedule raising the min replicas above the static value")
+			startTime := metav1.NewTime(fakeclock.Now().Add(-time.Hour))
+			endTime := metav1.NewTime(fakeclock.Now().Add(time.Hour))
+			scheduledMinReplicas := int32(15)
+			horizontalreplicascaler.Spec.Schedules = []rrethyv1.ReplicaSchedule{
+				{Name: "event", StartTime: &startTime, EndTime: &endTime, MinReplicas: &scheduledMinReplicas},
+			}
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the deployment to check the replica count")
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, eventuallyTimeout, interval).Should(Equal(scheduledMinReplicas))
+
+			By("Getting the status of the scaler to check the active schedule")
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+			Expect(horizontalreplicascaler.Status.ActiveSchedules).To(Equal([]string{"event"}))
+		})
+
 		It("Should respect max replicas", func() {
 			By("Getting the existing scaler")
 			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
@@ -354,6 +750,58 @@ var _ = Describe("HorizontalReplicaScaler Controller", func() {
 			}, consistentlyTimeout, interval).Should(Equal(int32(initialDeploymentScale)))
 		})
 
+		It("Should not scale up again during the scale up cooldown", func() {
+			By("Getting the existing scaler")
+			var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+			Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+			Expect(horizontalreplicascaler.Status.LastScaleTime).NotTo(BeNil())
+
+			By("Adding a scale up cooldown and raising the static value")
+			horizontalreplicascaler.Spec.ScalingBehavior.ScaleUp.Cooldown = metav1.Duration{Duration: time.Hour}
+			horizontalreplicascaler.Spec.Metrics[0].Target.Value = "15"
+			Expect(k8sClient.Update(ctx, &horizontalreplicascaler)).To(Succeed())
+
+			By("Getting the deployment to check the replica count")
+			Consistently(func() int32 {
+				var deployment appsv1.Deployment
+				Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+				return *deployment.Spec.Replicas
+			}, consistentlyTimeout, interval).Should(Equal(int32(initialDeploymentScale)))
+		})
+
+		It("Should detect flapping when the direction of scaling keeps reversing", func() {
+			By("Enabling flapping detection")
+			Eventually(func() error {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				horizontalreplicascaler.Spec.ScalingBehavior.FlappingDetection = &rrethyv1.FlappingDetection{MaxReversals: 1}
+				return k8sClient.Update(ctx, &horizontalreplicascaler)
+			}, eventuallyTimeout, interval).Should(Succeed())
+
+			for _, value := range []int32{5, 10, 5, 10} {
+				By(fmt.Sprintf("Changing the static value to %d", value))
+				Eventually(func() error {
+					var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+					Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+					horizontalreplicascaler.Spec.Metrics[0].Target.Value = strconv.Itoa(int(value))
+					return k8sClient.Update(ctx, &horizontalreplicascaler)
+				}, eventuallyTimeout, interval).Should(Succeed())
+
+				Eventually(func() int32 {
+					var deployment appsv1.Deployment
+					Expect(k8sClient.Get(ctx, defaultDeploymentNamespacedName, &deployment)).To(Succeed())
+					return *deployment.Spec.Replicas
+				}, eventuallyTimeout, interval).Should(Equal(value))
+			}
+
+			By("Getting the status of the scaler to check the condition")
+			Eventually(func() bool {
+				var horizontalreplicascaler rrethyv1.HorizontalReplicaScaler
+				Expect(k8sClient.Get(ctx, defaultScalerNamespacedName, &horizontalreplicascaler)).To(Succeed())
+				return meta.IsStatusConditionTrue(horizontalreplicascaler.Status.Conditions, rrethyv1.ConditionTypeFlapping)
+			}, eventuallyTimeout, interval).Should(BeTrue())
+		})
+
 		It("Should not scale if desired replicas are equal to target", func() {
 			stabilizationWindowDuration := 1 * time.Second
 
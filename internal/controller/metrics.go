@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// stabilizationWindowKeys is the number of keys in the stabilization windows, labeled by the scaling direction of the window.
+var stabilizationWindowKeys = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "horizontalreplicascaler_stabilization_window_keys",
+	Help: "Number of keys in the stabilization windows of the scalers.",
+}, []string{"direction"})
+
+// metricValueRejections is the number of metric values rejected as outliers.
+var metricValueRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "horizontalreplicascaler_metric_value_rejections_total",
+	Help: "Number of metric values rejected as outliers.",
+}, []string{"namespace", "scaler", "metric", "reason"})
+
+func init() {
+	metrics.Registry.MustRegister(stabilizationWindowKeys, metricValueRejections)
+}
+
+// deleteScalerMetrics deletes the series of the scaler so the series of deleted scalers don't accumulate.
+func deleteScalerMetrics(namespace, name string) {
+	metricValueRejections.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "scaler": name})
+}
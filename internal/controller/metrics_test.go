@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteScalerMetrics(t *testing.T) {
+	metricValueRejections.Reset()
+	metricValueRejections.WithLabelValues("default", "deleted", "load", "OutOfBounds").Inc()
+	metricValueRejections.WithLabelValues("default", "deleted", "queue", "Spike").Inc()
+	metricValueRejections.WithLabelValues("default", "kept", "load", "OutOfBounds").Inc()
+	metricValueRejections.WithLabelValues("other", "deleted", "load", "OutOfBounds").Inc()
+
+	deleteScalerMetrics("default", "deleted")
+
+	assert.Equal(t, 2, testutil.CollectAndCount(metricValueRejections))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metricValueRejections.WithLabelValues("default", "kept", "load", "OutOfBounds")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metricValueRejections.WithLabelValues("other", "deleted", "load", "OutOfBounds")))
+}
@@ -0,0 +1,412 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+func TestClampReplicas(t *testing.T) {
+	tests := []struct {
+		testName string
+		replicas float64
+		expected int32
+	}{
+		{"in range", 5, 5},
+		{"zero", 0, 0},
+		{"negative", -3, 0},
+		{"max int32", math.MaxInt32, math.MaxInt32},
+		{"above max int32", math.MaxInt32 + 1, math.MaxInt32},
+		{"positive infinity", math.Inf(1), math.MaxInt32},
+		{"negative infinity", math.Inf(-1), 0},
+		{"NaN", math.NaN(), 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			assert.Equal(t, test.expected, clampReplicas(test.replicas))
+		})
+	}
+}
+
+func TestGetReplicasForMetric_Overflow(t *testing.T) {
+	tests := []struct {
+		testName        string
+		metric          rrethyv1.MetricSpec
+		value           float64
+		currentReplicas int32
+		state           podState
+		expected        int32
+	}{
+		{
+			testName:        "value target",
+			metric:          rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType, Target: &rrethyv1.TargetSec{Type: rrethyv1.ValueTargetType, Value: "1"}},
+			value:           1e12,
+			currentReplicas: 10,
+			expected:        math.MaxInt32,
+		},
+		{
+			testName:        "pod-average target",
+			metric:          rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType, Target: &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "0.001"}},
+			value:           1e12,
+			currentReplicas: 10,
+			expected:        math.MaxInt32,
+		},
+		{
+			testName:        "per-pod pod-average target",
+			metric:          rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType, Target: &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "0.001", PerPod: true}},
+			value:           1e12,
+			currentReplicas: 10,
+			state:           podState{readyPods: 10},
+			expected:        math.MaxInt32,
+		},
+		{
+			testName:        "utilization target",
+			metric:          rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType, Target: &rrethyv1.TargetSec{Type: rrethyv1.UtilizationTargetType, Value: "1", Resource: "cpu"}},
+			value:           1e12,
+			currentReplicas: 10,
+			state:           podState{requests: 1},
+			expected:        math.MaxInt32,
+		},
+		{
+			testName:        "static metric",
+			metric:          rrethyv1.MetricSpec{Type: rrethyv1.StaticMetricType},
+			value:           1e12,
+			currentReplicas: 10,
+			expected:        math.MaxInt32,
+		},
+		{
+			testName:        "negative static metric",
+			metric:          rrethyv1.MetricSpec{Type: rrethyv1.StaticMetricType},
+			value:           -1,
+			currentReplicas: 10,
+			expected:        0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			replicas, _, err := getReplicasForMetric(test.metric, test.value, test.currentReplicas, test.state, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, replicas)
+		})
+	}
+}
+
+func TestGetReplicasForMetric_MissingTarget(t *testing.T) {
+	_, _, err := getReplicasForMetric(rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType}, 1, 10, podState{}, 0)
+	assert.Error(t, err)
+}
+
+func TestAggregate_Overflow(t *testing.T) {
+	replicas, err := aggregate(rrethyv1.MedianAggregationType, []int32{math.MaxInt32, math.MaxInt32}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(math.MaxInt32), replicas)
+}
+
+func TestAggregateMetricValues_Cron(t *testing.T) {
+	tests := []struct {
+		testName         string
+		aggregation      rrethyv1.AggregationType
+		metricValues     []metricValue
+		expectedReplicas int32
+	}{
+		{
+			testName:    "cron outside its windows doesn't lower the average",
+			aggregation: rrethyv1.AverageAggregationType,
+			metricValues: []metricValue{
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType}, replicas: 6},
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType}, replicas: 4},
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.CronMetricType}, replicas: 0},
+			},
+			expectedReplicas: 5,
+		},
+		{
+			testName:    "cron within a window raises the average",
+			aggregation: rrethyv1.AverageAggregationType,
+			metricValues: []metricValue{
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType}, replicas: 6},
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType}, replicas: 4},
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.CronMetricType}, replicas: 10},
+			},
+			expectedReplicas: 10,
+		},
+		{
+			testName:    "cron outside its windows doesn't lower the min",
+			aggregation: rrethyv1.MinAggregationType,
+			metricValues: []metricValue{
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType}, replicas: 6},
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.CronMetricType}, replicas: 0},
+			},
+			expectedReplicas: 6,
+		},
+		{
+			testName:    "cron with a ceiling role",
+			aggregation: rrethyv1.AverageAggregationType,
+			metricValues: []metricValue{
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType}, replicas: 6},
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.CronMetricType, Role: rrethyv1.CeilingMetricRole}, replicas: 3},
+			},
+			expectedReplicas: 3,
+		},
+		{
+			testName:    "cron still applies when the other metrics are excluded",
+			aggregation: rrethyv1.AverageAggregationType,
+			metricValues: []metricValue{
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType}, excluded: true},
+				{metric: rrethyv1.MetricSpec{Type: rrethyv1.CronMetricType}, replicas: 10},
+			},
+			expectedReplicas: 10,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			horizontalReplicaScaler := &rrethyv1.HorizontalReplicaScaler{
+				Spec:   rrethyv1.HorizontalReplicaScalerSpec{Aggregation: test.aggregation},
+				Status: rrethyv1.HorizontalReplicaScalerStatus{CurrentReplicas: 4},
+			}
+			replicas, err := (&HorizontalReplicaScalerReconciler{}).aggregateMetricValues(context.Background(), horizontalReplicaScaler, test.metricValues)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedReplicas, replicas)
+		})
+	}
+}
+
+func TestGetPIDReplicas_ScaledToZero(t *testing.T) {
+	now := time.Date(1997, time.November, 7, 0, 0, 0, 0, time.UTC)
+	r := &HorizontalReplicaScalerReconciler{Clock: clock.NewFakePassiveClock(now)}
+	horizontalReplicaScaler := &rrethyv1.HorizontalReplicaScaler{
+		Status: rrethyv1.HorizontalReplicaScalerStatus{
+			PIDStates: []rrethyv1.PIDState{{Metric: "load", Integral: "NaN", Error: "+Inf", Derivative: "0", LastUpdateTime: metav1.NewTime(now)}},
+		},
+	}
+	metric := rrethyv1.MetricSpec{
+		Name:       "load",
+		Type:       rrethyv1.PrometheusMetricType,
+		Controller: rrethyv1.PIDControllerType,
+		Target:     &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10"},
+	}
+
+	replicas, err := r.getPIDReplicas(horizontalReplicaScaler, "load", metric, 30, 0, podState{}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), replicas)
+	assert.Equal(t, []rrethyv1.PIDState{{Metric: "load", Integral: "0", Error: "2", Derivative: "0", LastUpdateTime: metav1.NewTime(now)}}, horizontalReplicaScaler.Status.PIDStates)
+
+	_, err = r.getPIDReplicas(horizontalReplicaScaler, "load", metric, math.Inf(1), 0, podState{}, 0)
+	assert.Error(t, err)
+}
+
+func TestGetPerPodReplicas(t *testing.T) {
+	tests := []struct {
+		testName         string
+		value            float64
+		currentReplicas  int32
+		state            podState
+		expectedRatio    float64
+		expectedReplicas int32
+		expectedErr      bool
+	}{
+		{
+			testName:         "scale up with ready pods",
+			value:            60,
+			currentReplicas:  4,
+			state:            podState{readyPods: 4},
+			expectedRatio:    1.5,
+			expectedReplicas: 6,
+		},
+		{
+			testName:         "scale up assumes unready pods report 0",
+			value:            90,
+			currentReplicas:  6,
+			state:            podState{readyPods: 4, unreadyPods: 2},
+			expectedRatio:    1.5,
+			expectedReplicas: 9,
+		},
+		{
+			testName:         "scale up assumes missing pods report 0",
+			value:            90,
+			currentReplicas:  6,
+			state:            podState{readyPods: 4},
+			expectedRatio:    1.5,
+			expectedReplicas: 9,
+		},
+		{
+			testName:         "scale up cancelled by unready pods",
+			value:            60,
+			currentReplicas:  6,
+			state:            podState{readyPods: 4, unreadyPods: 2},
+			expectedRatio:    1,
+			expectedReplicas: 6,
+		},
+		{
+			testName:         "scale down with ready pods",
+			value:            20,
+			currentReplicas:  4,
+			state:            podState{readyPods: 4},
+			expectedRatio:    0.5,
+			expectedReplicas: 2,
+		},
+		{
+			testName:         "scale down ignores unready pods",
+			value:            20,
+			currentReplicas:  6,
+			state:            podState{readyPods: 4, unreadyPods: 2},
+			expectedRatio:    0.5,
+			expectedReplicas: 2,
+		},
+		{
+			testName:         "scale down assumes missing pods are at the target",
+			value:            20,
+			currentReplicas:  6,
+			state:            podState{readyPods: 4},
+			expectedRatio:    40.0 / 60,
+			expectedReplicas: 4,
+		},
+		{
+			testName:         "at the target",
+			value:            40,
+			currentReplicas:  4,
+			state:            podState{readyPods: 4},
+			expectedRatio:    1,
+			expectedReplicas: 4,
+		},
+		{
+			testName:        "no ready pods",
+			value:           40,
+			currentReplicas: 4,
+			state:           podState{unreadyPods: 4},
+			expectedErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			ratio, replicas, err := getPerPodReplicas(test.value, 10, test.currentReplicas, test.state)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, test.expectedRatio, ratio, 1e-9)
+			assert.Equal(t, test.expectedReplicas, replicas)
+		})
+	}
+}
+
+func TestNewMetricValue_CronScaledToZero(t *testing.T) {
+	r := &HorizontalReplicaScalerReconciler{Client: fake.NewClientBuilder().Build()}
+	horizontalReplicaScaler := &rrethyv1.HorizontalReplicaScaler{ObjectMeta: metav1.ObjectMeta{Name: "test-scaler", Namespace: "default"}}
+	scaleSubresource := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 0}}
+	metric := rrethyv1.MetricSpec{Type: rrethyv1.CronMetricType}
+
+	value, err := r.newMetricValue(context.Background(), horizontalReplicaScaler, scaleSubresource, 0, metric, 3, map[string]float64{})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), value.replicas)
+	assert.NotEqual(t, MetricReasonNoPods, value.reason)
+}
+
+func TestApplyPredictive_InvalidSpec(t *testing.T) {
+	tests := []struct {
+		testName   string
+		predictive rrethyv1.Predictive
+	}{
+		{
+			testName:   "zero lookahead",
+			predictive: rrethyv1.Predictive{},
+		},
+		{
+			testName:   "history longer than the samples kept",
+			predictive: rrethyv1.Predictive{Lookahead: metav1.Duration{Duration: time.Minute}, Step: metav1.Duration{Duration: time.Second}, History: metav1.Duration{Duration: 24 * time.Hour}},
+		},
+		{
+			testName:   "default holt-winters history longer than the samples kept",
+			predictive: rrethyv1.Predictive{Forecaster: rrethyv1.HoltWintersForecasterType, Lookahead: metav1.Duration{Duration: time.Minute}, Step: metav1.Duration{Duration: 10 * time.Second}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			r := &HorizontalReplicaScalerReconciler{Client: fake.NewClientBuilder().Build(), APIReader: fake.NewClientBuilder().Build()}
+			horizontalReplicaScaler := &rrethyv1.HorizontalReplicaScaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-scaler", Namespace: "default"},
+				Spec:       rrethyv1.HorizontalReplicaScalerSpec{Predictive: &test.predictive},
+			}
+			err := r.applyPredictive(context.Background(), horizontalReplicaScaler, &autoscalingv1.Scale{}, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGetPodState_InitializationPeriod(t *testing.T) {
+	now := time.Date(1997, time.November, 7, 0, 0, 0, 0, time.UTC)
+	newPod := func(name string, startedAgo time.Duration, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "test"}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				StartTime:  &metav1.Time{Time: now.Add(-startedAgo)},
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+	r := &HorizontalReplicaScalerReconciler{
+		Client: fake.NewClientBuilder().WithObjects(
+			newPod("ready", time.Minute, corev1.ConditionTrue),
+			newPod("initializing", 10*time.Second, corev1.ConditionTrue),
+			newPod("unready", time.Minute, corev1.ConditionFalse),
+		).Build(),
+		Clock: clock.NewFakePassiveClock(now),
+	}
+	horizontalReplicaScaler := &rrethyv1.HorizontalReplicaScaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-scaler", Namespace: "default"},
+		Spec:       rrethyv1.HorizontalReplicaScalerSpec{InitializationPeriod: metav1.Duration{Duration: 30 * time.Second}},
+	}
+	scaleSubresource := &autoscalingv1.Scale{Status: autoscalingv1.ScaleStatus{Replicas: 3, Selector: "app=test"}}
+	metric := rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType, Target: &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10", PerPod: true}}
+
+	state, err := r.getPodState(context.Background(), horizontalReplicaScaler, scaleSubresource, metric)
+	assert.NoError(t, err)
+	assert.Equal(t, podState{readyPods: 1, unreadyPods: 2}, state)
+}
+
+func TestGetStepReplicas(t *testing.T) {
+	steps := []rrethyv1.ScalingStep{
+		{UpperBound: "-0.2", Adjustment: -1},
+		{LowerBound: "0.2", UpperBound: "1", Adjustment: 1},
+		{LowerBound: "1", Adjustment: 3},
+	}
+	tests := []struct {
+		testName        string
+		target          *rrethyv1.TargetSec
+		value           float64
+		currentReplicas int32
+		expected        int32
+	}{
+		{"open lower step", &rrethyv1.TargetSec{Type: rrethyv1.ValueTargetType, Value: "10"}, 5, 4, 3},
+		{"bounded step", &rrethyv1.TargetSec{Type: rrethyv1.ValueTargetType, Value: "10"}, 15, 4, 5},
+		{"open upper step", &rrethyv1.TargetSec{Type: rrethyv1.ValueTargetType, Value: "10"}, 50, 4, 7},
+		{"no matching step", &rrethyv1.TargetSec{Type: rrethyv1.ValueTargetType, Value: "10"}, 10, 4, 4},
+		{"pod-average target scaled to zero", &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10"}, 50, 0, 3},
+		{"idle pod-average target scaled to zero", &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10"}, 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			metric := rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType, Controller: rrethyv1.StepControllerType, Steps: steps, Target: test.target}
+			replicas, err := getStepReplicas(metric, test.value, test.currentReplicas, podState{})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, replicas)
+		})
+	}
+}
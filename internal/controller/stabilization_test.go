@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+	"github.com/RRethy/horizontalreplicascaler/internal/stabilization"
+)
+
+func newStabilizationTestReconciler(t *testing.T, objects ...*rrethyv1.HorizontalReplicaScaler) *HorizontalReplicaScalerReconciler {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, rrethyv1.AddToScheme(scheme))
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, object := range objects {
+		builder = builder.WithObjects(object)
+	}
+	fakeClock := clock.NewFakeClock(time.Date(1997, time.November, 7, 0, 0, 0, 0, time.UTC))
+	return &HorizontalReplicaScalerReconciler{
+		Client:                       builder.Build(),
+		Scheme:                       scheme,
+		ScaleDownStabilizationWindow: stabilization.NewWindow(stabilization.MaxRollingWindow, stabilization.WithClock(fakeClock)),
+		ScaleUpStabilizationWindow:   stabilization.NewWindow(stabilization.MinRollingWindow, stabilization.WithClock(fakeClock)),
+		Clock:                        fakeClock,
+	}
+}
+
+func newStabilizationTestScaler(name, target string) *rrethyv1.HorizontalReplicaScaler {
+	return &rrethyv1.HorizontalReplicaScaler{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: rrethyv1.HorizontalReplicaScalerSpec{
+			ScaleTargetRef: rrethyv1.ScaleTargetRef{Group: "apps", Kind: "Deployment", Name: target},
+		},
+	}
+}
+
+// windowKeys returns the sorted keys of the stabilization window.
+func windowKeys(window *stabilization.Window) []string {
+	var keys []string
+	window.DeleteFunc(func(key string) bool {
+		keys = append(keys, key)
+		return false
+	})
+	slices.Sort(keys)
+	return keys
+}
+
+func TestForgetScaler(t *testing.T) {
+	deleted := newStabilizationTestScaler("deleted", "web")
+	kept := newStabilizationTestScaler("kept", "web")
+	r := newStabilizationTestReconciler(t)
+	for _, key := range []string{
+		stabilizationWindowKey(deleted),
+		stabilizationWindowKey(newStabilizationTestScaler("deleted", "previous-web")),
+		stabilizationWindowKey(kept),
+	} {
+		r.ScaleDownStabilizationWindow.Stabilize(key, 3, time.Minute)
+		r.ScaleUpStabilizationWindow.Stabilize(key, 3, time.Minute)
+	}
+
+	deleted.DeletionTimestamp = &metav1.Time{Time: r.Clock.Now()}
+	_, err := r.Reconcile(context.Background(), deleted)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{stabilizationWindowKey(kept)}, windowKeys(r.ScaleDownStabilizationWindow))
+	assert.Equal(t, []string{stabilizationWindowKey(kept)}, windowKeys(r.ScaleUpStabilizationWindow))
+	assert.Equal(t, float64(1), testutil.ToFloat64(stabilizationWindowKeys.WithLabelValues("down")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(stabilizationWindowKeys.WithLabelValues("up")))
+}
+
+func TestSweepStabilizationWindows(t *testing.T) {
+	retargeted := newStabilizationTestScaler("retargeted", "new-web")
+	r := newStabilizationTestReconciler(t, retargeted)
+	oldKey := stabilizationWindowKey(newStabilizationTestScaler("retargeted", "old-web"))
+	newKey := stabilizationWindowKey(retargeted)
+	missingKey := stabilizationWindowKey(newStabilizationTestScaler("missing", "web"))
+	for _, key := range []string{oldKey, newKey, missingKey} {
+		r.ScaleDownStabilizationWindow.Stabilize(key, 3, time.Minute)
+		r.ScaleUpStabilizationWindow.Stabilize(key, 3, time.Minute)
+	}
+
+	assert.NoError(t, r.sweepStabilizationWindows(context.Background()))
+
+	assert.Equal(t, []string{newKey}, windowKeys(r.ScaleDownStabilizationWindow))
+	assert.Equal(t, []string{newKey}, windowKeys(r.ScaleUpStabilizationWindow))
+	assert.Equal(t, float64(1), testutil.ToFloat64(stabilizationWindowKeys.WithLabelValues("down")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(stabilizationWindowKeys.WithLabelValues("up")))
+}
+
+func TestApplyScalingBehavior_StabilizationType(t *testing.T) {
+	tests := []struct {
+		testName          string
+		rules             rrethyv1.ScalingRules
+		expectedStatistic stabilization.Statistic
+		expectedReplicas  int32
+	}{
+		{
+			testName:          "defaults to max",
+			rules:             rrethyv1.ScalingRules{},
+			expectedStatistic: stabilization.Statistic{Type: stabilization.MaxRollingWindow},
+			expectedReplicas:  6,
+		},
+		{
+			testName:          "percentile",
+			rules:             rrethyv1.ScalingRules{StabilizationType: rrethyv1.PercentileStabilizationType, StabilizationPercentile: 50},
+			expectedStatistic: stabilization.Statistic{Type: stabilization.PercentileRollingWindow, Percentile: 50},
+			expectedReplicas:  4,
+		},
+		{
+			testName:          "percentile defaults to p90",
+			rules:             rrethyv1.ScalingRules{StabilizationType: rrethyv1.PercentileStabilizationType},
+			expectedStatistic: stabilization.Statistic{Type: stabilization.PercentileRollingWindow, Percentile: 90},
+			expectedReplicas:  6,
+		},
+		{
+			testName:          "ewma",
+			rules:             rrethyv1.ScalingRules{StabilizationType: rrethyv1.EWMAStabilizationType},
+			expectedStatistic: stabilization.Statistic{Type: stabilization.EWMARollingWindow},
+			// The average of every recommendation is about 4.16 since the earlier ones keep some weight.
+			expectedReplicas: 4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			r := newStabilizationTestReconciler(t)
+			fakeClock := r.Clock.(*clock.FakeClock)
+			horizontalReplicaScaler := newStabilizationTestScaler("scaler", "web")
+			test.rules.StabilizationWindow = metav1.Duration{Duration: 2500 * time.Millisecond}
+			horizontalReplicaScaler.Spec.ScalingBehavior.ScaleDown = test.rules
+
+			var replicas int32
+			for _, desiredReplicas := range []int32{2, 10, 6, 4, 3} {
+				replicas = r.applyScalingBehavior(context.Background(), horizontalReplicaScaler, 10, desiredReplicas)
+				fakeClock.Step(time.Second)
+			}
+			// The recommendations of 2 and 10 left the window, leaving 6, 4 and 3.
+			assert.Equal(t, test.expectedReplicas, replicas)
+			assert.Equal(t, test.expectedStatistic, r.ScaleDownStabilizationWindow.Statistics[stabilizationWindowKey(horizontalReplicaScaler)])
+		})
+	}
+}
@@ -26,6 +26,8 @@ import (
 
 	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
 	"github.com/RRethy/horizontalreplicascaler/internal/metric"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/kubeobjectcount"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/scalerref"
 	"github.com/RRethy/horizontalreplicascaler/internal/stabilization"
 	// +kubebuilder:scaffold:imports
 )
@@ -80,7 +82,10 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
 
-	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme.Scheme})
+	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme: scheme.Scheme,
+		Client: client.Options{Cache: &client.CacheOptions{Unstructured: true}},
+	})
 	Expect(err).ToNot(HaveOccurred())
 
 	eventRecorder = record.NewFakeRecorder(10)
@@ -98,13 +103,18 @@ var _ = BeforeSuite(func() {
 	scaleUpStabilizationWindow = stabilization.NewWindow(stabilization.MinRollingWindow, stabilization.WithClock(fakeclock))
 
 	err = (&HorizontalReplicaScalerReconciler{
-		Client:                       k8sManager.GetClient(),
-		Scheme:                       k8sManager.GetScheme(),
-		Recorder:                     eventRecorder,
-		ScaleClient:                  scaleClient,
-		MetricClient:                 metric.NewClient(),
+		Client:      k8sManager.GetClient(),
+		APIReader:   k8sManager.GetAPIReader(),
+		Scheme:      k8sManager.GetScheme(),
+		Recorder:    eventRecorder,
+		ScaleClient: scaleClient,
+		MetricClient: metric.NewClient(
+			metric.WithKubeObjectCountClient(kubeobjectcount.NewClient(k8sManager.GetClient())),
+			metric.WithScalerRefClient(scalerref.NewClient(k8sManager.GetClient())),
+		),
 		ScaleDownStabilizationWindow: scaleDownStabilizationWindow,
 		ScaleUpStabilizationWindow:   scaleUpStabilizationWindow,
+		Clock:                        fakeclock,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -0,0 +1,78 @@
+package forecast
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Linear fits a least squares line to the points, which are evenly spaced, and returns its value
+// horizon points after the last point.
+func Linear(points []float64, horizon int) (float64, error) {
+	n := len(points)
+	if n < 2 {
+		return 0, fmt.Errorf("linear forecast needs at least 2 points, got %d", n)
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range points {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	slope := (float64(n)*sumXY - sumX*sumY) / (float64(n)*sumXX - sumX*sumX)
+	intercept := (sumY - slope*sumX) / float64(n)
+	return intercept + slope*float64(n-1+horizon), nil
+}
+
+// HoltWinters runs additive Holt-Winters triple exponential smoothing over the points, which are evenly spaced
+// with seasons of seasonLength points, and returns the forecast horizon points after the last point.
+// The level, trend and seasonal components are smoothed by alpha, beta and gamma respectively.
+// At least two seasons of points are needed to initialize the trend and seasonal components.
+func HoltWinters(points []float64, seasonLength int, alpha, beta, gamma float64, horizon int) (float64, error) {
+	if seasonLength < 1 {
+		return 0, errors.New("season length must be positive")
+	}
+	n := len(points)
+	if n < 2*seasonLength {
+		return 0, fmt.Errorf("holt-winters forecast needs at least 2 seasons of %d points, got %d", seasonLength, n)
+	}
+	if horizon < 1 {
+		return 0, errors.New("horizon must be positive")
+	}
+
+	// The seasonal components start as the average deviation of each point of every complete season from the
+	// detrended mean of its season, and the level starts at the end of the first season.
+	seasons := n / seasonLength
+	seasonMeans := make([]float64, seasons)
+	for j := range seasonMeans {
+		seasonMeans[j] = mean(points[j*seasonLength : (j+1)*seasonLength])
+	}
+	trend := (seasonMeans[1] - seasonMeans[0]) / float64(seasonLength)
+	level := seasonMeans[0] + trend*float64(seasonLength-1)/2
+	seasonal := make([]float64, n)
+	for i := 0; i < seasonLength; i++ {
+		for j := range seasonMeans {
+			seasonal[i] += points[j*seasonLength+i] - (seasonMeans[j] + trend*(float64(i)-float64(seasonLength-1)/2))
+		}
+		seasonal[i] /= float64(seasons)
+	}
+
+	for t := seasonLength; t < n; t++ {
+		previousLevel := level
+		level = alpha*(points[t]-seasonal[t-seasonLength]) + (1-alpha)*(level+trend)
+		trend = beta*(level-previousLevel) + (1-beta)*trend
+		seasonal[t] = gamma*(points[t]-level) + (1-gamma)*seasonal[t-seasonLength]
+	}
+
+	return level + float64(horizon)*trend + seasonal[n-seasonLength+(horizon-1)%seasonLength], nil
+}
+
+func mean(points []float64) float64 {
+	var sum float64
+	for _, point := range points {
+		sum += point
+	}
+	return sum / float64(len(points))
+}
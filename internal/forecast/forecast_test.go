@@ -0,0 +1,71 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinear(t *testing.T) {
+	tests := []struct {
+		testName      string
+		points        []float64
+		horizon       int
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{testName: "extends a rising trend", points: []float64{1, 2, 3, 4}, horizon: 2, expectedValue: 6},
+		{testName: "fits noisy points", points: []float64{1, 3, 2, 4}, horizon: 1, expectedValue: 4.5},
+		{testName: "flat points", points: []float64{5, 5, 5}, horizon: 10, expectedValue: 5},
+		{testName: "too few points is an error", points: []float64{5}, horizon: 1, expectedErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			value, err := Linear(test.points, test.horizon)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, test.expectedValue, value, 1e-9)
+		})
+	}
+}
+
+func TestHoltWinters(t *testing.T) {
+	// A rising trend with a season of 4 points which peaks at the third point.
+	season := []float64{0, 5, 10, 5}
+	var points []float64
+	for i := 0; i < 12; i++ {
+		points = append(points, float64(i)+season[i%4])
+	}
+
+	tests := []struct {
+		testName      string
+		points        []float64
+		seasonLength  int
+		horizon       int
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{testName: "forecasts the next peak", points: points, seasonLength: 4, horizon: 3, expectedValue: 14 + 10},
+		{testName: "forecasts the next trough", points: points, seasonLength: 4, horizon: 1, expectedValue: 12},
+		{testName: "less than two seasons is an error", points: points[:7], seasonLength: 4, horizon: 1, expectedErr: true},
+		{testName: "non positive horizon is an error", points: points, seasonLength: 4, horizon: 0, expectedErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			value, err := HoltWinters(test.points, test.seasonLength, 0.5, 0.1, 0.3, test.horizon)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, math.IsNaN(value))
+			assert.InDelta(t, test.expectedValue, value, 1e-6)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package forecast
+
+import (
+	"time"
+)
+
+// MaxSamples is the most samples a History keeps, which bounds the size of a history at about 120KiB once encoded.
+const MaxSamples = 4096
+
+// Sample is the value of a metric in the step starting at Timestamp, in unix seconds.
+type Sample struct {
+	Timestamp int64   `json:"t"`
+	Value     float64 `json:"v"`
+}
+
+// History is the samples of a metric, ordered by timestamp, with at most one sample per step.
+type History []Sample
+
+// Add records the value in the step containing now, replacing the value already recorded in that step,
+// and drops samples older than the retention or beyond the MaxSamples most recent ones.
+func (h History) Add(now time.Time, value float64, step, retention time.Duration) History {
+	timestamp := now.Truncate(step).Unix()
+	if len(h) > 0 && h[len(h)-1].Timestamp >= timestamp {
+		h[len(h)-1].Value = value
+	} else {
+		h = append(h, Sample{Timestamp: timestamp, Value: value})
+	}
+
+	cutoff := now.Add(-retention).Unix()
+	i := 0
+	for i < len(h) && h[i].Timestamp < cutoff {
+		i++
+	}
+	i = max(i, len(h)-MaxSamples)
+	return h[i:]
+}
+
+// Points returns the value of every step from the first sample to the last, carrying the previous
+// value forward into steps without a sample. The step must be at least a second since the timestamps
+// of the samples are in seconds.
+func (h History) Points(step time.Duration) []float64 {
+	if len(h) == 0 || step < time.Second {
+		return nil
+	}
+
+	steps := func(from, to int64) int {
+		return int(time.Duration(to-from) * time.Second / step)
+	}
+	points := make([]float64, 0, steps(h[0].Timestamp, h[len(h)-1].Timestamp)+1)
+	for i, sample := range h {
+		if i > 0 {
+			for j := 1; j < steps(h[i-1].Timestamp, sample.Timestamp); j++ {
+				points = append(points, h[i-1].Value)
+			}
+		}
+		points = append(points, sample.Value)
+	}
+	return points
+}
@@ -0,0 +1,50 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var initialTime = time.Date(1997, time.November, 7, 0, 0, 0, 0, time.UTC)
+
+func TestHistory_Add(t *testing.T) {
+	var history History
+	history = history.Add(initialTime, 1, time.Minute, 3*time.Minute)
+	history = history.Add(initialTime.Add(30*time.Second), 2, time.Minute, 3*time.Minute)
+	assert.Equal(t, History{{Timestamp: initialTime.Unix(), Value: 2}}, history, "samples in the same step are replaced")
+
+	history = history.Add(initialTime.Add(time.Minute), 3, time.Minute, 3*time.Minute)
+	history = history.Add(initialTime.Add(3*time.Minute+10*time.Second), 4, time.Minute, 3*time.Minute)
+	assert.Equal(t, History{
+		{Timestamp: initialTime.Add(time.Minute).Unix(), Value: 3},
+		{Timestamp: initialTime.Add(3 * time.Minute).Unix(), Value: 4},
+	}, history, "samples older than the retention are dropped")
+}
+
+func TestHistory_Points(t *testing.T) {
+	history := History{
+		{Timestamp: initialTime.Unix(), Value: 1},
+		{Timestamp: initialTime.Add(time.Minute).Unix(), Value: 2},
+		{Timestamp: initialTime.Add(4 * time.Minute).Unix(), Value: 5},
+	}
+	assert.Equal(t, []float64{1, 2, 2, 2, 5}, history.Points(time.Minute))
+	assert.Nil(t, History{}.Points(time.Minute))
+	assert.Nil(t, history.Points(500*time.Millisecond), "steps under a second are rejected")
+
+	history = History{
+		{Timestamp: initialTime.Unix(), Value: 1},
+		{Timestamp: initialTime.Add(3 * time.Second).Unix(), Value: 3},
+	}
+	assert.Equal(t, []float64{1, 1, 3}, history.Points(1500*time.Millisecond), "steps aren't truncated to seconds")
+}
+
+func TestHistory_Add_MaxSamples(t *testing.T) {
+	var history History
+	for i := 0; i < MaxSamples+10; i++ {
+		history = history.Add(initialTime.Add(time.Duration(i)*time.Second), float64(i), time.Second, 24*time.Hour)
+	}
+	assert.Len(t, history, MaxSamples)
+	assert.Equal(t, Sample{Timestamp: initialTime.Add(10 * time.Second).Unix(), Value: 10}, history[0], "the oldest samples are dropped")
+}
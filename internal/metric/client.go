@@ -1,10 +1,22 @@
 package metric
 
 import (
+	"context"
 	"fmt"
 
 	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/cron"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/elasticsearch"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/graphite"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/influxdb"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/kafka"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/kubeobjectcount"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/otlp"
 	"github.com/RRethy/horizontalreplicascaler/internal/metric/prometheus"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/rabbitmq"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/resource"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/scalerref"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/sql"
 	"github.com/RRethy/horizontalreplicascaler/internal/metric/static"
 )
 
@@ -12,10 +24,21 @@ var (
 	_ Interface = &Client{}
 	_ Interface = &static.Client{}
 	_ Interface = &prometheus.Client{}
+	_ Interface = &kafka.Client{}
+	_ Interface = &rabbitmq.Client{}
+	_ Interface = &cron.Client{}
+	_ Interface = &kubeobjectcount.Client{}
+	_ Interface = &otlp.Client{}
+	_ Interface = &graphite.Client{}
+	_ Interface = &influxdb.Client{}
+	_ Interface = &elasticsearch.Client{}
+	_ Interface = &sql.Client{}
+	_ Interface = &scalerref.Client{}
+	_ Interface = &resource.Client{}
 )
 
 type Interface interface {
-	GetValue(rrethyv1.MetricSpec) (float64, error)
+	GetValue(ctx context.Context, namespace string, metric rrethyv1.MetricSpec) (float64, error)
 }
 
 type Option func(*Client)
@@ -28,15 +51,85 @@ func WithPrometheusClient(client Interface) Option {
 	return func(c *Client) { c.prometheusClient = client }
 }
 
+func WithKafkaClient(client Interface) Option {
+	return func(c *Client) { c.kafkaClient = client }
+}
+
+func WithRabbitMQClient(client Interface) Option {
+	return func(c *Client) { c.rabbitmqClient = client }
+}
+
+func WithCronClient(client Interface) Option {
+	return func(c *Client) { c.cronClient = client }
+}
+
+// WithKubeObjectCountClient sets the client for kube-object-count metrics.
+// There is no default since it needs a client for the cluster.
+func WithKubeObjectCountClient(client Interface) Option {
+	return func(c *Client) { c.kubeObjectCountClient = client }
+}
+
+// WithOTLPClient sets the client for otlp metrics.
+// There is no default since it needs the store of a running receiver.
+func WithOTLPClient(client Interface) Option {
+	return func(c *Client) { c.otlpClient = client }
+}
+
+func WithGraphiteClient(client Interface) Option {
+	return func(c *Client) { c.graphiteClient = client }
+}
+
+func WithInfluxDBClient(client Interface) Option {
+	return func(c *Client) { c.influxdbClient = client }
+}
+
+func WithElasticsearchClient(client Interface) Option {
+	return func(c *Client) { c.elasticsearchClient = client }
+}
+
+func WithSQLClient(client Interface) Option {
+	return func(c *Client) { c.sqlClient = client }
+}
+
+// WithScalerRefClient sets the client for scaler-ref metrics.
+// There is no default since it needs a client for the cluster.
+func WithScalerRefClient(client Interface) Option {
+	return func(c *Client) { c.scalerRefClient = client }
+}
+
+// WithResourceClient sets the client for resource metrics.
+// There is no default since it needs a client for the cluster.
+func WithResourceClient(client Interface) Option {
+	return func(c *Client) { c.resourceClient = client }
+}
+
 type Client struct {
-	staticClient     Interface
-	prometheusClient Interface
+	staticClient          Interface
+	prometheusClient      Interface
+	kafkaClient           Interface
+	rabbitmqClient        Interface
+	cronClient            Interface
+	kubeObjectCountClient Interface
+	otlpClient            Interface
+	graphiteClient        Interface
+	influxdbClient        Interface
+	elasticsearchClient   Interface
+	sqlClient             Interface
+	scalerRefClient       Interface
+	resourceClient        Interface
 }
 
 func NewClient(opts ...Option) *Client {
 	client := &Client{
-		staticClient:     static.NewClient(),
-		prometheusClient: prometheus.NewClient(),
+		staticClient:        static.NewClient(),
+		prometheusClient:    prometheus.NewClient(),
+		kafkaClient:         kafka.NewClient(),
+		rabbitmqClient:      rabbitmq.NewClient(),
+		cronClient:          cron.NewClient(),
+		graphiteClient:      graphite.NewClient(),
+		influxdbClient:      influxdb.NewClient(),
+		elasticsearchClient: elasticsearch.NewClient(),
+		sqlClient:           sql.NewClient(),
 	}
 	for _, opt := range opts {
 		opt(client)
@@ -45,12 +138,48 @@ func NewClient(opts ...Option) *Client {
 	return client
 }
 
-func (c *Client) GetValue(metric rrethyv1.MetricSpec) (float64, error) {
+func (c *Client) GetValue(ctx context.Context, namespace string, metric rrethyv1.MetricSpec) (float64, error) {
 	switch metric.Type {
 	case rrethyv1.StaticMetricType:
-		return c.staticClient.GetValue(metric)
+		return c.staticClient.GetValue(ctx, namespace, metric)
 	case rrethyv1.PrometheusMetricType:
-		return c.prometheusClient.GetValue(metric)
+		return c.prometheusClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.KafkaMetricType:
+		return c.kafkaClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.RabbitMQMetricType:
+		return c.rabbitmqClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.CronMetricType:
+		return c.cronClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.KubeObjectCountMetricType:
+		if c.kubeObjectCountClient == nil {
+			return 0, fmt.Errorf("no client configured for metric type %s", metric.Type)
+		}
+		return c.kubeObjectCountClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.OTLPMetricType:
+		if c.otlpClient == nil {
+			return 0, fmt.Errorf("no client configured for metric type %s", metric.Type)
+		}
+		return c.otlpClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.GraphiteMetricType:
+		return c.graphiteClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.InfluxDBMetricType:
+		return c.influxdbClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.ElasticsearchMetricType:
+		return c.elasticsearchClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.SQLMetricType:
+		return c.sqlClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.ScalerRefMetricType:
+		if c.scalerRefClient == nil {
+			return 0, fmt.Errorf("no client configured for metric type %s", metric.Type)
+		}
+		return c.scalerRefClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.ResourceMetricType:
+		if c.resourceClient == nil {
+			return 0, fmt.Errorf("no client configured for metric type %s", metric.Type)
+		}
+		return c.resourceClient.GetValue(ctx, namespace, metric)
+	case rrethyv1.ExpressionMetricType:
+		return 0, fmt.Errorf("metric type %s depends on other metrics and must be evaluated by the caller", metric.Type)
 	default:
 		return 0, fmt.Errorf("unknown metric type %s", metric.Type)
 	}
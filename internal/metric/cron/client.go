@@ -0,0 +1,126 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/utils/clock"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+// parser parses standard 5 field cron expressions as well as descriptors such as @daily.
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// window is a recurring window of time during which the replicas should be at least the desired replicas.
+type window struct {
+	start    cron.Schedule
+	end      cron.Schedule
+	replicas int32
+}
+
+// Option is a function that configures a Client.
+type Option func(*Client)
+
+// WithClock sets the clock used by the client.
+// This is useful for testing where a fake clock is used.
+func WithClock(clock clock.PassiveClock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// Client is a metric client which returns desired replicas during scheduled windows.
+// The value is the number of replicas rather than a value compared against a target,
+// so it acts as a replica floor when combined with other metrics.
+type Client struct {
+	clock clock.PassiveClock
+}
+
+func NewClient(options ...Option) *Client {
+	c := &Client{clock: clock.RealClock{}}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// GetValue returns the max desired replicas of the windows that are currently active, or 0 if none are.
+//
+// The windows are configured with the "windows" key which is a newline separated list of windows
+// in the format "<start cron>; <end cron>; <replicas>", e.g. "0 9 * * 1-5; 0 17 * * 1-5; 10".
+// The cron expressions are evaluated in the "timezone" key which defaults to UTC.
+func (c *Client) GetValue(_ context.Context, _ string, metric rrethyv1.MetricSpec) (float64, error) {
+	location := time.UTC
+	if timezone := metric.Config["timezone"]; timezone != "" {
+		var err error
+		location, err = time.LoadLocation(timezone)
+		if err != nil {
+			return 0, fmt.Errorf("failed loading timezone %s: %w", timezone, err)
+		}
+	}
+
+	windows, err := parseWindows(metric.Config["windows"])
+	if err != nil {
+		return 0, err
+	}
+
+	now := c.clock.Now().In(location)
+	var replicas int32
+	for _, w := range windows {
+		if w.isActive(now) {
+			replicas = max(replicas, w.replicas)
+		}
+	}
+	return float64(replicas), nil
+}
+
+// isActive returns true if t is after a start of the window and before the following end.
+// This is the case when the window ends before it next starts.
+func (w window) isActive(t time.Time) bool {
+	return w.end.Next(t).Before(w.start.Next(t))
+}
+
+// parseWindows parses a newline separated list of windows.
+func parseWindows(s string) ([]window, error) {
+	var windows []window
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, ";")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("window %q must be in the format \"<start cron>; <end cron>; <replicas>\"", line)
+		}
+
+		start, err := parser.Parse(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing start of window %q: %w", line, err)
+		}
+		end, err := parser.Parse(strings.TrimSpace(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing end of window %q: %w", line, err)
+		}
+		replicas, err := strconv.ParseInt(strings.TrimSpace(fields[2]), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing replicas of window %q: %w", line, err)
+		}
+		if replicas < 0 {
+			return nil, fmt.Errorf("replicas of window %q must not be negative", line)
+		}
+
+		windows = append(windows, window{start: start, end: end, replicas: int32(replicas)})
+	}
+
+	if len(windows) == 0 {
+		return nil, errors.New("windows is required")
+	}
+	return windows, nil
+}
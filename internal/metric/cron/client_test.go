@@ -0,0 +1,114 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	clock "k8s.io/utils/clock/testing"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+// monday is a Monday at midnight UTC.
+var monday = time.Date(1997, time.November, 10, 0, 0, 0, 0, time.UTC)
+
+func TestClient_GetValue(t *testing.T) {
+	tests := []struct {
+		testName      string
+		currentTime   time.Time
+		config        map[string]string
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{
+			testName:      "inside a window",
+			currentTime:   monday.Add(10 * time.Hour),
+			config:        map[string]string{"windows": "0 9 * * 1-5; 0 17 * * 1-5; 10"},
+			expectedValue: 10,
+		},
+		{
+			testName:      "at the start of a window",
+			currentTime:   monday.Add(9 * time.Hour),
+			config:        map[string]string{"windows": "0 9 * * 1-5; 0 17 * * 1-5; 10"},
+			expectedValue: 10,
+		},
+		{
+			testName:      "outside a window",
+			currentTime:   monday.Add(18 * time.Hour),
+			config:        map[string]string{"windows": "0 9 * * 1-5; 0 17 * * 1-5; 10"},
+			expectedValue: 0,
+		},
+		{
+			testName:      "outside a window on the weekend",
+			currentTime:   monday.Add(-24*time.Hour + 10*time.Hour),
+			config:        map[string]string{"windows": "0 9 * * 1-5; 0 17 * * 1-5; 10"},
+			expectedValue: 0,
+		},
+		{
+			testName:      "window spanning midnight",
+			currentTime:   monday.Add(1 * time.Hour),
+			config:        map[string]string{"windows": "0 22 * * *; 0 2 * * *; 3"},
+			expectedValue: 3,
+		},
+		{
+			testName:    "overlapping windows use the max replicas",
+			currentTime: monday.Add(9*time.Hour + 30*time.Minute),
+			config: map[string]string{"windows": `
+				0 9 * * 1-5; 0 17 * * 1-5; 10
+				0 8 * * 1; 0 10 * * 1; 25
+			`},
+			expectedValue: 25,
+		},
+		{
+			testName:      "windows are evaluated in the timezone",
+			currentTime:   monday.Add(14 * time.Hour),
+			config:        map[string]string{"timezone": "America/Toronto", "windows": "0 9 * * 1-5; 0 17 * * 1-5; 10"},
+			expectedValue: 10,
+		},
+		{
+			testName:      "windows outside the timezone are inactive",
+			currentTime:   monday.Add(10 * time.Hour),
+			config:        map[string]string{"timezone": "America/Toronto", "windows": "0 9 * * 1-5; 0 17 * * 1-5; 10"},
+			expectedValue: 0,
+		},
+		{
+			testName:    "invalid timezone is an error",
+			currentTime: monday,
+			config:      map[string]string{"timezone": "Mars/Olympus_Mons", "windows": "0 9 * * 1-5; 0 17 * * 1-5; 10"},
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid cron expression is an error",
+			currentTime: monday,
+			config:      map[string]string{"windows": "0 9 * *; 0 17 * * 1-5; 10"},
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid window format is an error",
+			currentTime: monday,
+			config:      map[string]string{"windows": "0 9 * * 1-5; 10"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing windows is an error",
+			currentTime: monday,
+			config:      map[string]string{},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			c := NewClient(WithClock(clock.NewFakePassiveClock(test.currentTime)))
+			value, err := c.GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.CronMetricType, Config: test.config})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+const (
+	defaultQuery   = `{"query":{"match_all":{}}}`
+	defaultTimeout = 10 * time.Second
+)
+
+// searchResponse is the subset of the _search response that is used.
+// The total hits are either a number (Elasticsearch 6) or an object with a value (Elasticsearch 7+ and OpenSearch).
+type searchResponse struct {
+	Hits struct {
+		Total json.RawMessage `json:"total"`
+	} `json:"hits"`
+	Aggregations map[string]any `json:"aggregations"`
+}
+
+// Client is a metric client which runs searches against Elasticsearch or OpenSearch.
+type Client struct {
+	httpClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: defaultTimeout}}
+}
+
+// GetValue runs the search in the "query" key, a query DSL body which defaults to match_all,
+// against the "index" key, which may be a comma separated list of index patterns.
+// It returns hits.total unless the "aggregation" key is set, in which case it returns the value of
+// the aggregation at the dotted path, e.g. "backlog" for aggregations.backlog.value or "stats.max" for
+// aggregations.stats.max.
+// Credentials are either the "apiKey" key or the "username" and "password" keys, usually from a Secret.
+func (c *Client) GetValue(ctx context.Context, _ string, metric rrethyv1.MetricSpec) (float64, error) {
+	host := metric.Config["host"]
+	if host == "" {
+		return 0, errors.New("host is required")
+	}
+	index := metric.Config["index"]
+	if index == "" {
+		return 0, errors.New("index is required")
+	}
+	query := metric.Config["query"]
+	if query == "" {
+		query = defaultQuery
+	}
+	if !json.Valid([]byte(query)) {
+		return 0, errors.New("query must be a valid json query dsl body")
+	}
+
+	// The hits themselves are never used so only the total and aggregations are requested.
+	params := url.Values{"size": {"0"}, "track_total_hits": {"true"}}
+	endpoint := fmt.Sprintf("%s/%s/_search?%s", strings.TrimSuffix(host, "/"), url.PathEscape(index), params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(query))
+	if err != nil {
+		return 0, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey := metric.Config["apiKey"]; apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+apiKey)
+	} else if username := metric.Config["username"]; username != "" {
+		req.SetBasicAuth(username, metric.Config["password"])
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("querying elasticsearch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("querying elasticsearch: unexpected status %s", resp.Status)
+	}
+
+	var search searchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&search); err != nil {
+		return 0, fmt.Errorf("decoding elasticsearch response: %w", err)
+	}
+
+	if aggregation := metric.Config["aggregation"]; aggregation != "" {
+		return aggregationValue(search.Aggregations, aggregation)
+	}
+	return totalHits(search.Hits.Total)
+}
+
+// totalHits returns the total hits which is either a number or an object with a value.
+func totalHits(total json.RawMessage) (float64, error) {
+	if len(total) == 0 {
+		return 0, errors.New("response has no hits.total")
+	}
+
+	var value float64
+	if err := json.Unmarshal(total, &value); err == nil {
+		return value, nil
+	}
+	var object struct {
+		Value *float64 `json:"value"`
+	}
+	if err := json.Unmarshal(total, &object); err != nil || object.Value == nil {
+		return 0, fmt.Errorf("unexpected hits.total %s", string(total))
+	}
+	return *object.Value, nil
+}
+
+// aggregationValue returns the number at the dotted path in the aggregations.
+// If the path ends at an object, such as a metric aggregation, its value field is used.
+func aggregationValue(aggregations map[string]any, path string) (float64, error) {
+	var current any = aggregations
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return 0, fmt.Errorf("aggregation %s not found", path)
+		}
+		current, ok = object[key]
+		if !ok {
+			return 0, fmt.Errorf("aggregation %s not found", path)
+		}
+	}
+
+	if object, ok := current.(map[string]any); ok {
+		current = object["value"]
+	}
+	switch value := current.(type) {
+	case float64:
+		return value, nil
+	case nil:
+		return 0, fmt.Errorf("aggregation %s has no value", path)
+	default:
+		return 0, fmt.Errorf("aggregation %s has non numeric value %v", path, value)
+	}
+}
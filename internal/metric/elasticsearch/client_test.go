@@ -0,0 +1,149 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+// newFakeElasticsearch returns a fake search API which serves the response for each index
+// and records the body of the last search.
+func newFakeElasticsearch(t *testing.T, responses map[string]string, lastBody *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "elastic" || pass != "changeme" {
+			if r.Header.Get("Authorization") != "ApiKey secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		if r.Method != http.MethodPost || r.URL.Query().Get("size") != "0" || r.URL.Query().Get("track_total_hits") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.True(t, json.Valid(body))
+		*lastBody = string(body)
+
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err = w.Write([]byte(response))
+		assert.NoError(t, err)
+	}))
+}
+
+func TestClient_GetValue(t *testing.T) {
+	responses := map[string]string{
+		"/logs-*/_search":        `{"hits":{"total":{"value":1234,"relation":"eq"},"hits":[]},"aggregations":{"backlog":{"value":56},"by_status":{"buckets":[]},"stats":{"count":3,"max":9.5,"avg":null}}}`,
+		"/legacy/_search":        `{"hits":{"total":77,"hits":[]}}`,
+		"/logs-a,logs-b/_search": `{"hits":{"total":{"value":5,"relation":"eq"},"hits":[]}}`,
+	}
+
+	tests := []struct {
+		testName      string
+		config        map[string]string
+		expectedValue float64
+		expectedBody  string
+		expectedErr   bool
+	}{
+		{
+			testName:      "total hits with the default query",
+			config:        map[string]string{"index": "logs-*"},
+			expectedValue: 1234,
+			expectedBody:  defaultQuery,
+		},
+		{
+			testName:      "total hits as a number",
+			config:        map[string]string{"index": "legacy", "query": `{"query":{"term":{"processed":false}}}`},
+			expectedValue: 77,
+			expectedBody:  `{"query":{"term":{"processed":false}}}`,
+		},
+		{
+			testName:      "index lists",
+			config:        map[string]string{"index": "logs-a,logs-b"},
+			expectedValue: 5,
+			expectedBody:  defaultQuery,
+		},
+		{
+			testName:      "metric aggregation value",
+			config:        map[string]string{"index": "logs-*", "aggregation": "backlog"},
+			expectedValue: 56,
+			expectedBody:  defaultQuery,
+		},
+		{
+			testName:      "dotted aggregation path",
+			config:        map[string]string{"index": "logs-*", "aggregation": "stats.max"},
+			expectedValue: 9.5,
+			expectedBody:  defaultQuery,
+		},
+		{
+			testName:      "api key authentication",
+			config:        map[string]string{"index": "logs-*", "username": "", "apiKey": "secret"},
+			expectedValue: 1234,
+			expectedBody:  defaultQuery,
+		},
+		{
+			testName:    "null aggregation value is an error",
+			config:      map[string]string{"index": "logs-*", "aggregation": "stats.avg"},
+			expectedErr: true,
+		},
+		{
+			testName:    "non numeric aggregation is an error",
+			config:      map[string]string{"index": "logs-*", "aggregation": "by_status"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing aggregation is an error",
+			config:      map[string]string{"index": "logs-*", "aggregation": "missing"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing index is an error",
+			config:      map[string]string{"index": "missing"},
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid query is an error",
+			config:      map[string]string{"index": "logs-*", "query": `{"query":`},
+			expectedErr: true,
+		},
+		{
+			testName:    "wrong credentials are an error",
+			config:      map[string]string{"index": "logs-*", "password": "wrong"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			var lastBody string
+			server := newFakeElasticsearch(t, responses, &lastBody)
+			defer server.Close()
+
+			config := map[string]string{"host": server.URL, "username": "elastic", "password": "changeme"}
+			for key, value := range test.config {
+				config[key] = value
+			}
+
+			value, err := NewClient().GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.ElasticsearchMetricType, Config: config})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+			assert.Equal(t, test.expectedBody, lastBody)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"k8s.io/utils/lru"
+)
+
+// maxPrograms bounds the number of cached programs so expressions which are edited or removed are eventually evicted.
+const maxPrograms = 1024
+
+// programs caches the most recently used compiled programs keyed by the expression and the names of the variables.
+var programs = lru.New(maxPrograms)
+
+// Evaluate evaluates the CEL expression with each of the values declared as a double variable.
+// The expression must evaluate to a number, e.g. "requests_per_second / latency_budget".
+// Since every variable is a double, numeric literals must be written as doubles, e.g. "2.0".
+func Evaluate(expression string, values map[string]float64) (float64, error) {
+	program, err := compile(expression, values)
+	if err != nil {
+		return 0, err
+	}
+
+	activation := make(map[string]any, len(values))
+	for name, value := range values {
+		activation[name] = value
+	}
+
+	out, _, err := program.Eval(activation)
+	if err != nil {
+		return 0, fmt.Errorf("evaluating expression %q: %w", expression, err)
+	}
+
+	result, err := toFloat64(out)
+	if err != nil {
+		return 0, fmt.Errorf("evaluating expression %q: %w", expression, err)
+	}
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, fmt.Errorf("expression %q evaluated to %v", expression, result)
+	}
+	return result, nil
+}
+
+// compile returns the compiled program for the expression, compiling and caching it if needed.
+func compile(expression string, values map[string]float64) (cel.Program, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	key := expression + "\x00" + strings.Join(names, ",")
+	if program, ok := programs.Get(key); ok {
+		return program.(cel.Program), nil
+	}
+
+	env, ast, err := check(expression, names)
+	if err != nil {
+		return nil, err
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("compiling expression %q: %w", expression, err)
+	}
+
+	programs.Add(key, program)
+	return program, nil
+}
+
+// References returns the names of the variables out of names which the expression references, in sorted order.
+func References(expression string, names []string) ([]string, error) {
+	_, ast, err := check(expression, names)
+	if err != nil {
+		return nil, err
+	}
+	checked, err := cel.AstToCheckedExpr(ast)
+	if err != nil {
+		return nil, fmt.Errorf("checking expression %q: %w", expression, err)
+	}
+
+	var references []string
+	for _, reference := range checked.GetReferenceMap() {
+		if slices.Contains(names, reference.GetName()) && !slices.Contains(references, reference.GetName()) {
+			references = append(references, reference.GetName())
+		}
+	}
+	slices.Sort(references)
+	return references, nil
+}
+
+// check parses and type checks the expression with each of the names declared as a double variable.
+func check(expression string, names []string) (*cel.Env, *cel.Ast, error) {
+	options := make([]cel.EnvOption, 0, len(names))
+	for _, name := range names {
+		options = append(options, cel.Variable(name, cel.DoubleType))
+	}
+	env, err := cel.NewEnv(options...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating expression environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, nil, fmt.Errorf("compiling expression %q: %w", expression, issues.Err())
+	}
+	return env, ast, nil
+}
+
+// toFloat64 converts a numeric CEL value to a float64.
+func toFloat64(value ref.Val) (float64, error) {
+	switch v := value.(type) {
+	case types.Double:
+		return float64(v), nil
+	case types.Int:
+		return float64(v), nil
+	case types.Uint:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("result must be a number but was %s", value.Type().TypeName())
+	}
+}
@@ -0,0 +1,96 @@
+package expression
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		testName      string
+		expression    string
+		values        map[string]float64
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{
+			testName:      "divides two metrics",
+			expression:    "requests_per_second / latency_budget",
+			values:        map[string]float64{"requests_per_second": 300, "latency_budget": 0.5},
+			expectedValue: 600,
+		},
+		{
+			testName:      "uses double literals",
+			expression:    "queue * 2.0 + 1.0",
+			values:        map[string]float64{"queue": 5},
+			expectedValue: 11,
+		},
+		{
+			testName:      "supports conditionals",
+			expression:    "errors > 10.0 ? errors : 0.0",
+			values:        map[string]float64{"errors": 4},
+			expectedValue: 0,
+		},
+		{
+			testName:      "integer results are converted",
+			expression:    "int(queue) / 3",
+			values:        map[string]float64{"queue": 10},
+			expectedValue: 3,
+		},
+		{
+			testName:    "unknown variables are an error",
+			expression:  "missing / 2.0",
+			values:      map[string]float64{"queue": 10},
+			expectedErr: true,
+		},
+		{
+			testName:    "non numeric results are an error",
+			expression:  "queue > 2.0",
+			values:      map[string]float64{"queue": 10},
+			expectedErr: true,
+		},
+		{
+			testName:    "division by zero is an error",
+			expression:  "queue / zero",
+			values:      map[string]float64{"queue": 10, "zero": 0},
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid syntax is an error",
+			expression:  "queue +",
+			values:      map[string]float64{"queue": 10},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			value, err := Evaluate(test.expression, test.values)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
+
+func TestEvaluate_BoundsCachedPrograms(t *testing.T) {
+	for i := 0; i < maxPrograms+10; i++ {
+		_, err := Evaluate(fmt.Sprintf("queue + %d.0", i), map[string]float64{"queue": 1})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, maxPrograms, programs.Len())
+}
+
+func TestReferences(t *testing.T) {
+	references, err := References("queue > 10.0 ? queue / workers : backlog", []string{"workers", "queue", "backlog", "unused"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"backlog", "queue", "workers"}, references)
+
+	_, err = References("queue / missing", []string{"queue"})
+	assert.Error(t, err)
+}
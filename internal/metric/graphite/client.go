@@ -0,0 +1,115 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/reducer"
+)
+
+const (
+	defaultFrom    = "-5min"
+	defaultTimeout = 10 * time.Second
+)
+
+// series is a single series of the render API json response.
+// Each datapoint is a [value, timestamp] pair where the value is null if there is no data.
+type series struct {
+	Target     string        `json:"target"`
+	Datapoints [][2]*float64 `json:"datapoints"`
+}
+
+// Client is a metric client which queries the Graphite render API.
+type Client struct {
+	httpClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: defaultTimeout}}
+}
+
+// GetValue returns the reduced value of the series returned by the render API for the target.
+// Each series is reduced with the "reducer" key, which defaults to last, and the results are summed.
+// Null datapoints are skipped.
+func (c *Client) GetValue(ctx context.Context, _ string, metric rrethyv1.MetricSpec) (float64, error) {
+	host := metric.Config["host"]
+	if host == "" {
+		return 0, errors.New("host is required")
+	}
+	target := metric.Config["target"]
+	if target == "" {
+		return 0, errors.New("target is required")
+	}
+	from := metric.Config["from"]
+	if from == "" {
+		from = defaultFrom
+	}
+	reducerName := metric.Config["reducer"]
+	if err := reducer.Validate(reducerName); err != nil {
+		return 0, err
+	}
+
+	query := url.Values{}
+	query.Set("target", target)
+	query.Set("from", from)
+	if until := metric.Config["until"]; until != "" {
+		query.Set("until", until)
+	}
+	query.Set("format", "json")
+
+	endpoint := fmt.Sprintf("%s/render?%s", strings.TrimSuffix(host, "/"), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("creating request: %w", err)
+	}
+	if username := metric.Config["username"]; username != "" {
+		req.SetBasicAuth(username, metric.Config["password"])
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("querying graphite render api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("querying graphite render api: unexpected status %s", resp.Status)
+	}
+
+	var result []series
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("decoding graphite render api response: %w", err)
+	}
+
+	var value float64
+	var found bool
+	for _, s := range result {
+		var values []float64
+		for _, datapoint := range s.Datapoints {
+			if datapoint[0] != nil {
+				values = append(values, *datapoint[0])
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		seriesValue, err := reducer.Reduce(reducerName, values)
+		if err != nil {
+			return 0, fmt.Errorf("reducing series %s: %w", s.Target, err)
+		}
+		value += seriesValue
+		found = true
+	}
+
+	if !found {
+		return 0, fmt.Errorf("no data for graphite target %s", target)
+	}
+	return value, nil
+}
@@ -0,0 +1,114 @@
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+// newFakeRenderAPI returns a fake render API which serves the response for the target
+// and records the query parameters of the last request.
+func newFakeRenderAPI(t *testing.T, responses map[string]string, lastQuery *map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/render" || r.URL.Query().Get("format") != "json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		*lastQuery = map[string]string{"from": r.URL.Query().Get("from"), "until": r.URL.Query().Get("until")}
+		response, ok := responses[r.URL.Query().Get("target")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, err := w.Write([]byte(response))
+		assert.NoError(t, err)
+	}))
+}
+
+func TestClient_GetValue(t *testing.T) {
+	responses := map[string]string{
+		"queue.depth":     `[{"target":"queue.depth","datapoints":[[3,1700000000],[null,1700000060],[5,1700000120],[null,1700000180]]}]`,
+		"queue.*.depth":   `[{"target":"queue.a.depth","datapoints":[[3,1700000000],[5,1700000060]]},{"target":"queue.b.depth","datapoints":[[1,1700000000],[2,1700000060]]}]`,
+		"queue.empty":     `[{"target":"queue.empty","datapoints":[[null,1700000000]]}]`,
+		"queue.no-series": `[]`,
+	}
+
+	tests := []struct {
+		testName      string
+		config        map[string]string
+		expectedValue float64
+		expectedQuery map[string]string
+		expectedErr   bool
+	}{
+		{
+			testName:      "last non-null value is the default",
+			config:        map[string]string{"target": "queue.depth"},
+			expectedValue: 5,
+			expectedQuery: map[string]string{"from": "-5min", "until": ""},
+		},
+		{
+			testName:      "reducer and time range are configurable",
+			config:        map[string]string{"target": "queue.depth", "reducer": "avg", "from": "-1h", "until": "now"},
+			expectedValue: 4,
+			expectedQuery: map[string]string{"from": "-1h", "until": "now"},
+		},
+		{
+			testName:      "series are reduced and summed",
+			config:        map[string]string{"target": "queue.*.depth", "reducer": "max"},
+			expectedValue: 7,
+			expectedQuery: map[string]string{"from": "-5min", "until": ""},
+		},
+		{
+			testName:    "only null datapoints is an error",
+			config:      map[string]string{"target": "queue.empty"},
+			expectedErr: true,
+		},
+		{
+			testName:    "no series is an error",
+			config:      map[string]string{"target": "queue.no-series"},
+			expectedErr: true,
+		},
+		{
+			testName:    "failed render is an error",
+			config:      map[string]string{"target": "sumSeries("},
+			expectedErr: true,
+		},
+		{
+			testName:    "unknown reducer is an error",
+			config:      map[string]string{"target": "queue.depth", "reducer": "p99"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing target is an error",
+			config:      map[string]string{},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			var lastQuery map[string]string
+			server := newFakeRenderAPI(t, responses, &lastQuery)
+			defer server.Close()
+
+			config := map[string]string{"host": server.URL}
+			for key, value := range test.config {
+				config[key] = value
+			}
+
+			value, err := NewClient().GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.GraphiteMetricType, Config: config})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+			assert.Equal(t, test.expectedQuery, lastQuery)
+		})
+	}
+}
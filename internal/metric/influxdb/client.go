@@ -0,0 +1,252 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+	"github.com/RRethy/horizontalreplicascaler/internal/metric/reducer"
+)
+
+const (
+	// InfluxQLLanguage queries the InfluxDB 1.x /query endpoint.
+	InfluxQLLanguage = "influxql"
+	// FluxLanguage queries the InfluxDB 2.x /api/v2/query endpoint.
+	FluxLanguage = "flux"
+
+	defaultFluxColumn = "_value"
+	defaultTimeout    = 10 * time.Second
+)
+
+// influxQLResponse is the subset of the /query response that is used.
+type influxQLResponse struct {
+	Results []struct {
+		Series []struct {
+			Name    string   `json:"name"`
+			Columns []string `json:"columns"`
+			Values  [][]any  `json:"values"`
+		} `json:"series"`
+		Error string `json:"error"`
+	} `json:"results"`
+	Error string `json:"error"`
+}
+
+// Client is a metric client which queries InfluxDB with either InfluxQL or Flux.
+type Client struct {
+	httpClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: defaultTimeout}}
+}
+
+// GetValue returns the reduced value of the series returned by the query.
+// The query language is configured with the "language" key which is one of influxql or flux, and defaults to influxql.
+// Each series (or Flux table) is reduced with the "reducer" key, which defaults to last, and the results are summed.
+// The "column" key is the column holding the values, which defaults to the first non-time column for InfluxQL
+// and _value for Flux.
+func (c *Client) GetValue(ctx context.Context, _ string, metric rrethyv1.MetricSpec) (float64, error) {
+	host := metric.Config["host"]
+	if host == "" {
+		return 0, errors.New("host is required")
+	}
+	query := metric.Config["query"]
+	if query == "" {
+		return 0, errors.New("query is required")
+	}
+	reducerName := metric.Config["reducer"]
+	if err := reducer.Validate(reducerName); err != nil {
+		return 0, err
+	}
+
+	var req *http.Request
+	var err error
+	language := metric.Config["language"]
+	switch language {
+	case "", InfluxQLLanguage:
+		params := url.Values{}
+		params.Set("q", query)
+		if database := metric.Config["database"]; database != "" {
+			params.Set("db", database)
+		}
+		endpoint := fmt.Sprintf("%s/query?%s", strings.TrimSuffix(host, "/"), params.Encode())
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return 0, fmt.Errorf("creating request: %w", err)
+		}
+	case FluxLanguage:
+		org := metric.Config["org"]
+		if org == "" {
+			return 0, errors.New("org is required for flux queries")
+		}
+		endpoint := fmt.Sprintf("%s/api/v2/query?%s", strings.TrimSuffix(host, "/"), url.Values{"org": {org}}.Encode())
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(query))
+		if err != nil {
+			return 0, fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/vnd.flux")
+		req.Header.Set("Accept", "application/csv")
+	default:
+		return 0, fmt.Errorf("unknown language %s", language)
+	}
+
+	if token := metric.Config["token"]; token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	} else if username := metric.Config["username"]; username != "" {
+		req.SetBasicAuth(username, metric.Config["password"])
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("querying influxdb: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("querying influxdb: unexpected status %s", resp.Status)
+	}
+
+	var series [][]float64
+	if language == FluxLanguage {
+		column := metric.Config["column"]
+		if column == "" {
+			column = defaultFluxColumn
+		}
+		series, err = parseFlux(resp.Body, column)
+	} else {
+		series, err = parseInfluxQL(resp.Body, metric.Config["column"])
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var value float64
+	var found bool
+	for _, values := range series {
+		if len(values) == 0 {
+			continue
+		}
+		seriesValue, err := reducer.Reduce(reducerName, values)
+		if err != nil {
+			return 0, err
+		}
+		value += seriesValue
+		found = true
+	}
+
+	if !found {
+		return 0, fmt.Errorf("no data for influxdb query %s", query)
+	}
+	return value, nil
+}
+
+// parseInfluxQL returns the values of the column for each series of the /query json response.
+// Null values are skipped.
+func parseInfluxQL(body io.Reader, column string) ([][]float64, error) {
+	var resp influxQLResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decoding influxdb response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("influxdb query failed: %s", resp.Error)
+	}
+
+	var series [][]float64
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("influxdb query failed: %s", result.Error)
+		}
+		for _, s := range result.Series {
+			index := slices.IndexFunc(s.Columns, func(c string) bool {
+				if column == "" {
+					return c != "time"
+				}
+				return c == column
+			})
+			if index == -1 {
+				return nil, fmt.Errorf("series %s has no value column", s.Name)
+			}
+
+			var values []float64
+			for _, row := range s.Values {
+				if index >= len(row) || row[index] == nil {
+					continue
+				}
+				value, ok := row[index].(float64)
+				if !ok {
+					return nil, fmt.Errorf("series %s has non numeric value %v", s.Name, row[index])
+				}
+				values = append(values, value)
+			}
+			series = append(series, values)
+		}
+	}
+	return series, nil
+}
+
+// parseFlux returns the values of the column for each table of the annotated csv response.
+// Each table starts with a header row and tables are identified by the result and table columns.
+// Empty values are skipped.
+func parseFlux(body io.Reader, column string) ([][]float64, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	tables := map[string][]float64{}
+	var order []string
+	valueIndex, resultIndex, tableIndex := -1, -1, -1
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding influxdb response: %w", err)
+		}
+
+		if slices.Contains(row, "table") && slices.Contains(row, "result") {
+			valueIndex = slices.Index(row, column)
+			resultIndex = slices.Index(row, "result")
+			tableIndex = slices.Index(row, "table")
+			if valueIndex == -1 {
+				return nil, fmt.Errorf("table has no %s column", column)
+			}
+			continue
+		}
+		if valueIndex == -1 {
+			return nil, errors.New("decoding influxdb response: missing header row")
+		}
+		if max(valueIndex, resultIndex, tableIndex) >= len(row) {
+			return nil, fmt.Errorf("decoding influxdb response: row has %d columns, fewer than its header", len(row))
+		}
+		if row[valueIndex] == "" {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(row[valueIndex], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing value %s: %w", row[valueIndex], err)
+		}
+		table := row[resultIndex] + "/" + row[tableIndex]
+		if _, ok := tables[table]; !ok {
+			order = append(order, table)
+		}
+		tables[table] = append(tables[table], value)
+	}
+
+	series := make([][]float64, 0, len(order))
+	for _, table := range order {
+		series = append(series, tables[table])
+	}
+	return series, nil
+}
@@ -0,0 +1,192 @@
+package influxdb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+const fluxResponse = `#datatype,string,long,dateTime:RFC3339,double,string
+#group,false,false,false,false,true
+#default,_result,,,,
+,result,table,_time,_value,host
+,,0,2024-01-01T00:00:00Z,2,a
+,,0,2024-01-01T00:01:00Z,4,a
+,,1,2024-01-01T00:00:00Z,10,b
+,,1,2024-01-01T00:01:00Z,,b
+`
+
+// newFakeInfluxDB returns a fake InfluxDB serving InfluxQL queries by query string and
+// Flux queries with a fixed response, both authenticated with the token.
+func newFakeInfluxDB(t *testing.T, token string, influxQLResponses map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/query":
+			if r.URL.Query().Get("db") != "telegraf" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			response, ok := influxQLResponses[r.URL.Query().Get("q")]
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, err := w.Write([]byte(response))
+			assert.NoError(t, err)
+		case "/api/v2/query":
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			if r.Method != http.MethodPost || r.URL.Query().Get("org") != "infra" || r.Header.Get("Content-Type") != "application/vnd.flux" || len(body) == 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, err = w.Write([]byte(fluxResponse))
+			assert.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestClient_GetValue(t *testing.T) {
+	influxQLResponses := map[string]string{
+		"SELECT mean(depth) FROM queue": `{"results":[{"statement_id":0,"series":[{"name":"queue","columns":["time","mean"],"values":[["2024-01-01T00:00:00Z",3],["2024-01-01T00:01:00Z",null],["2024-01-01T00:02:00Z",5]]}]}]}`,
+		"SELECT depth, max(depth) FROM queue GROUP BY host": `{"results":[{"statement_id":0,"series":[` +
+			`{"name":"queue","tags":{"host":"a"},"columns":["time","depth","max"],"values":[["2024-01-01T00:00:00Z",1,6]]},` +
+			`{"name":"queue","tags":{"host":"b"},"columns":["time","depth","max"],"values":[["2024-01-01T00:00:00Z",2,7]]}]}]}`,
+		"SELECT depth FROM missing": `{"results":[{"statement_id":0}]}`,
+		"SELECT bad":                `{"results":[{"statement_id":0,"error":"error parsing query"}]}`,
+	}
+
+	tests := []struct {
+		testName      string
+		config        map[string]string
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{
+			testName:      "influxql is the default language and last the default reducer",
+			config:        map[string]string{"query": "SELECT mean(depth) FROM queue", "database": "telegraf"},
+			expectedValue: 5,
+		},
+		{
+			testName:      "influxql series are reduced and summed",
+			config:        map[string]string{"query": "SELECT depth, max(depth) FROM queue GROUP BY host", "database": "telegraf", "reducer": "avg"},
+			expectedValue: 3,
+		},
+		{
+			testName:      "influxql column is configurable",
+			config:        map[string]string{"query": "SELECT depth, max(depth) FROM queue GROUP BY host", "database": "telegraf", "column": "max"},
+			expectedValue: 13,
+		},
+		{
+			testName:    "influxql without series is an error",
+			config:      map[string]string{"query": "SELECT depth FROM missing", "database": "telegraf"},
+			expectedErr: true,
+		},
+		{
+			testName:    "influxql statement error is an error",
+			config:      map[string]string{"query": "SELECT bad", "database": "telegraf"},
+			expectedErr: true,
+		},
+		{
+			testName:      "flux tables are reduced and summed",
+			config:        map[string]string{"language": "flux", "query": `from(bucket: "queue") |> range(start: -5m)`, "org": "infra", "reducer": "max"},
+			expectedValue: 14,
+		},
+		{
+			testName:    "flux without org is an error",
+			config:      map[string]string{"language": "flux", "query": `from(bucket: "queue")`},
+			expectedErr: true,
+		},
+		{
+			testName:    "flux with a missing column is an error",
+			config:      map[string]string{"language": "flux", "query": `from(bucket: "queue")`, "org": "infra", "column": "_missing"},
+			expectedErr: true,
+		},
+		{
+			testName:    "wrong token is an error",
+			config:      map[string]string{"query": "SELECT mean(depth) FROM queue", "database": "telegraf", "token": "wrong"},
+			expectedErr: true,
+		},
+		{
+			testName:    "unknown language is an error",
+			config:      map[string]string{"language": "sql", "query": "SELECT 1"},
+			expectedErr: true,
+		},
+		{
+			testName:    "unknown reducer is an error",
+			config:      map[string]string{"query": "SELECT mean(depth) FROM queue", "database": "telegraf", "reducer": "p99"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			server := newFakeInfluxDB(t, "secret", influxQLResponses)
+			defer server.Close()
+
+			config := map[string]string{"host": server.URL, "token": "secret"}
+			for key, value := range test.config {
+				config[key] = value
+			}
+
+			value, err := NewClient().GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.InfluxDBMetricType, Config: config})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
+
+func TestParseFlux(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		expectedSeries [][]float64
+		expectedErr    bool
+	}{
+		{
+			testName:       "result and table columns after the value column",
+			body:           "_value,result,table\n2,_result,0\n3,_result,1\n",
+			expectedSeries: [][]float64{{2}, {3}},
+		},
+		{
+			testName:    "truncated row",
+			body:        ",result,table,_time,_value\n,,0,2024-01-01T00:00:00Z,2\n,,0\n",
+			expectedErr: true,
+		},
+		{
+			testName:    "row missing the result and table columns",
+			body:        "_value,result,table\n2\n",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			series, err := parseFlux(strings.NewReader(test.body), "_value")
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedSeries, series)
+		})
+	}
+}
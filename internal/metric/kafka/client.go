@@ -0,0 +1,311 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+const (
+	// TotalLagType sums the lag of every partition of the topic.
+	TotalLagType = "total"
+	// MaxPartitionLagType uses the lag of the partition with the most lag.
+	MaxPartitionLagType = "max-partition"
+
+	// LatestOffsetResetPolicy treats partitions without a committed offset as having no lag.
+	LatestOffsetResetPolicy = "latest"
+	// EarliestOffsetResetPolicy treats partitions without a committed offset as lagging by every retained message.
+	EarliestOffsetResetPolicy = "earliest"
+)
+
+// config is the parsed configuration of a kafka metric.
+type config struct {
+	brokers               []string
+	topic                 string
+	consumerGroup         string
+	lagType               string
+	offsetResetPolicy     string
+	limitToPartitionCount bool
+	version               sarama.KafkaVersion
+	sasl                  string
+	username              string
+	password              string
+	tls                   bool
+	ca                    string
+	cert                  string
+	key                   string
+	insecureSkipVerify    bool
+}
+
+// Client is a metric client which computes the lag of a Kafka consumer group on a topic.
+type Client struct{}
+
+func NewClient() *Client {
+	return &Client{}
+}
+
+// GetValue returns the lag of the consumer group for the topic.
+// When limitToPartitionCount is set, the lag is capped so that the desired replicas never exceed the partition count
+// since extra consumers would be idle. This is only supported for pod-average targets.
+func (c *Client) GetValue(_ context.Context, _ string, metric rrethyv1.MetricSpec) (float64, error) {
+	cfg, err := parseConfig(metric.Config)
+	if err != nil {
+		return 0, err
+	}
+	if cfg.limitToPartitionCount && (metric.Target == nil || metric.Target.Type != rrethyv1.PodAverageTargetType) {
+		return 0, fmt.Errorf("limitToPartitionCount is only supported for %s targets", rrethyv1.PodAverageTargetType)
+	}
+
+	saramaConfig, err := cfg.saramaConfig()
+	if err != nil {
+		return 0, err
+	}
+
+	client, err := sarama.NewClient(cfg.brokers, saramaConfig)
+	if err != nil {
+		return 0, fmt.Errorf("creating kafka client: %w", err)
+	}
+	defer client.Close()
+
+	partitions, err := client.Partitions(cfg.topic)
+	if err != nil {
+		return 0, fmt.Errorf("getting partitions for topic %s: %w", cfg.topic, err)
+	}
+	if len(partitions) == 0 {
+		return 0, fmt.Errorf("topic %s has no partitions", cfg.topic)
+	}
+
+	lags, err := getPartitionLags(client, saramaConfig.Version, cfg, partitions)
+	if err != nil {
+		return 0, err
+	}
+
+	var lag int64
+	for _, partitionLag := range lags {
+		switch cfg.lagType {
+		case TotalLagType:
+			lag += partitionLag
+		case MaxPartitionLagType:
+			lag = max(lag, partitionLag)
+		}
+	}
+
+	value := float64(lag)
+	if cfg.limitToPartitionCount {
+		target, err := strconv.ParseFloat(metric.Target.Value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed parsing target value %s: %w", metric.Target.Value, err)
+		}
+		value = min(value, float64(len(partitions))*target)
+	}
+
+	return value, nil
+}
+
+// getPartitionLags returns the lag of the consumer group for each of the partitions.
+func getPartitionLags(client sarama.Client, version sarama.KafkaVersion, cfg *config, partitions []int32) (map[int32]int64, error) {
+	coordinator, err := client.Coordinator(cfg.consumerGroup)
+	if err != nil {
+		return nil, fmt.Errorf("getting coordinator for consumer group %s: %w", cfg.consumerGroup, err)
+	}
+
+	request := sarama.NewOffsetFetchRequest(version, cfg.consumerGroup, map[string][]int32{cfg.topic: partitions})
+	response, err := coordinator.FetchOffset(request)
+	if err != nil {
+		return nil, fmt.Errorf("fetching offsets for consumer group %s: %w", cfg.consumerGroup, err)
+	}
+	if !errors.Is(response.Err, sarama.ErrNoError) {
+		return nil, fmt.Errorf("fetching offsets for consumer group %s: %w", cfg.consumerGroup, response.Err)
+	}
+
+	lags := make(map[int32]int64, len(partitions))
+	for _, partition := range partitions {
+		block := response.GetBlock(cfg.topic, partition)
+		if block == nil {
+			return nil, fmt.Errorf("no offset for topic %s partition %d", cfg.topic, partition)
+		}
+		if !errors.Is(block.Err, sarama.ErrNoError) {
+			return nil, fmt.Errorf("fetching offset for topic %s partition %d: %w", cfg.topic, partition, block.Err)
+		}
+
+		latest, err := client.GetOffset(cfg.topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, fmt.Errorf("getting latest offset for topic %s partition %d: %w", cfg.topic, partition, err)
+		}
+
+		committed := block.Offset
+		if committed < 0 {
+			// The consumer group has never committed an offset for this partition.
+			if cfg.offsetResetPolicy == LatestOffsetResetPolicy {
+				lags[partition] = 0
+				continue
+			}
+			committed, err = client.GetOffset(cfg.topic, partition, sarama.OffsetOldest)
+			if err != nil {
+				return nil, fmt.Errorf("getting oldest offset for topic %s partition %d: %w", cfg.topic, partition, err)
+			}
+		}
+
+		lags[partition] = max(latest-committed, 0)
+	}
+
+	return lags, nil
+}
+
+// parseConfig parses the metric config into a config.
+func parseConfig(metricConfig map[string]string) (*config, error) {
+	cfg := &config{
+		topic:             metricConfig["topic"],
+		consumerGroup:     metricConfig["consumerGroup"],
+		lagType:           TotalLagType,
+		offsetResetPolicy: LatestOffsetResetPolicy,
+		version:           sarama.DefaultVersion,
+		sasl:              metricConfig["sasl"],
+		username:          metricConfig["username"],
+		password:          metricConfig["password"],
+		ca:                metricConfig["ca"],
+		cert:              metricConfig["cert"],
+		key:               metricConfig["key"],
+	}
+
+	for _, broker := range strings.Split(metricConfig["brokers"], ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			cfg.brokers = append(cfg.brokers, broker)
+		}
+	}
+	if len(cfg.brokers) == 0 {
+		return nil, errors.New("brokers is required")
+	}
+	if cfg.topic == "" {
+		return nil, errors.New("topic is required")
+	}
+	if cfg.consumerGroup == "" {
+		return nil, errors.New("consumerGroup is required")
+	}
+
+	if lagType, ok := metricConfig["lagType"]; ok {
+		switch lagType {
+		case TotalLagType, MaxPartitionLagType:
+			cfg.lagType = lagType
+		default:
+			return nil, fmt.Errorf("unknown lagType %s", lagType)
+		}
+	}
+
+	if offsetResetPolicy, ok := metricConfig["offsetResetPolicy"]; ok {
+		switch offsetResetPolicy {
+		case LatestOffsetResetPolicy, EarliestOffsetResetPolicy:
+			cfg.offsetResetPolicy = offsetResetPolicy
+		default:
+			return nil, fmt.Errorf("unknown offsetResetPolicy %s", offsetResetPolicy)
+		}
+	}
+
+	if version, ok := metricConfig["version"]; ok {
+		parsed, err := sarama.ParseKafkaVersion(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing version %s: %w", version, err)
+		}
+		cfg.version = parsed
+	}
+
+	var err error
+	if cfg.limitToPartitionCount, err = parseBool(metricConfig, "limitToPartitionCount"); err != nil {
+		return nil, err
+	}
+	if cfg.tls, err = parseBool(metricConfig, "tls"); err != nil {
+		return nil, err
+	}
+	if cfg.insecureSkipVerify, err = parseBool(metricConfig, "insecureSkipVerify"); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// parseBool parses the boolean value of key in metricConfig, defaulting to false if it is not set.
+func parseBool(metricConfig map[string]string, key string) (bool, error) {
+	value, ok := metricConfig[key]
+	if !ok {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("failed parsing %s %s: %w", key, value, err)
+	}
+	return parsed, nil
+}
+
+// saramaConfig returns the sarama config used to connect to the brokers.
+func (cfg *config) saramaConfig() (*sarama.Config, error) {
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.ClientID = "horizontalreplicascaler"
+	saramaConfig.Version = cfg.version
+
+	switch cfg.sasl {
+	case "":
+	case "plain":
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case "scram_sha256":
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		saramaConfig.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{HashGeneratorFcn: sha256HashGenerator} }
+	case "scram_sha512":
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		saramaConfig.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{HashGeneratorFcn: sha512HashGenerator} }
+	default:
+		return nil, fmt.Errorf("unknown sasl mechanism %s", cfg.sasl)
+	}
+	if cfg.sasl != "" {
+		if cfg.username == "" || cfg.password == "" {
+			return nil, errors.New("username and password are required when sasl is set")
+		}
+		saramaConfig.Net.SASL.Enable = true
+		saramaConfig.Net.SASL.User = cfg.username
+		saramaConfig.Net.SASL.Password = cfg.password
+	}
+
+	if cfg.tls {
+		tlsConfig, err := cfg.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		saramaConfig.Net.TLS.Enable = true
+		saramaConfig.Net.TLS.Config = tlsConfig
+	}
+
+	return saramaConfig, nil
+}
+
+// tlsConfig returns the tls config built from the PEM encoded ca, cert and key.
+func (cfg *config) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.insecureSkipVerify, //nolint:gosec // This is opt-in.
+	}
+
+	if cfg.ca != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.ca)) {
+			return nil, errors.New("failed parsing ca")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.cert != "" || cfg.key != "" {
+		certificate, err := tls.X509KeyPair([]byte(cfg.cert), []byte(cfg.key))
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing cert and key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,205 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+const (
+	testTopic         = "orders"
+	testConsumerGroup = "order-processor"
+)
+
+func newMockBroker(t *testing.T, latestOffsets, committedOffsets map[int32]int64) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+
+	metadataResponse := sarama.NewMockMetadataResponse(t).SetBroker(broker.Addr(), broker.BrokerID())
+	offsetResponse := sarama.NewMockOffsetResponse(t)
+	offsetFetchResponse := sarama.NewMockOffsetFetchResponse(t)
+	for partition, offset := range latestOffsets {
+		metadataResponse.SetLeader(testTopic, partition, broker.BrokerID())
+		offsetResponse.SetOffset(testTopic, partition, sarama.OffsetNewest, offset)
+		offsetResponse.SetOffset(testTopic, partition, sarama.OffsetOldest, 0)
+		committed, ok := committedOffsets[partition]
+		if !ok {
+			committed = -1
+		}
+		offsetFetchResponse.SetOffset(testConsumerGroup, testTopic, partition, committed, "", sarama.ErrNoError)
+	}
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest":        metadataResponse,
+		"OffsetRequest":          offsetResponse,
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).SetCoordinator(sarama.CoordinatorGroup, testConsumerGroup, broker),
+		"OffsetFetchRequest":     offsetFetchResponse,
+	})
+
+	return broker
+}
+
+func TestClient_GetValue(t *testing.T) {
+	tests := []struct {
+		testName         string
+		latestOffsets    map[int32]int64
+		committedOffsets map[int32]int64
+		config           map[string]string
+		target           *rrethyv1.TargetSec
+		expectedValue    float64
+		expectedErr      bool
+	}{
+		{
+			testName:         "total lag sums every partition",
+			latestOffsets:    map[int32]int64{0: 100, 1: 50, 2: 10},
+			committedOffsets: map[int32]int64{0: 40, 1: 50, 2: 5},
+			target:           &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10"},
+			expectedValue:    65,
+		},
+		{
+			testName:         "max partition lag uses the partition with the most lag",
+			latestOffsets:    map[int32]int64{0: 100, 1: 50, 2: 10},
+			committedOffsets: map[int32]int64{0: 40, 1: 50, 2: 5},
+			config:           map[string]string{"lagType": "max-partition"},
+			target:           &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10"},
+			expectedValue:    60,
+		},
+		{
+			testName:         "partitions without committed offsets have no lag with latest offset reset policy",
+			latestOffsets:    map[int32]int64{0: 100, 1: 50},
+			committedOffsets: map[int32]int64{0: 90},
+			target:           &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10"},
+			expectedValue:    10,
+		},
+		{
+			testName:         "partitions without committed offsets lag by all messages with earliest offset reset policy",
+			latestOffsets:    map[int32]int64{0: 100, 1: 50},
+			committedOffsets: map[int32]int64{0: 90},
+			config:           map[string]string{"offsetResetPolicy": "earliest"},
+			target:           &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10"},
+			expectedValue:    60,
+		},
+		{
+			testName:         "lag is capped at the partition count times the target",
+			latestOffsets:    map[int32]int64{0: 1000, 1: 1000},
+			committedOffsets: map[int32]int64{0: 0, 1: 0},
+			config:           map[string]string{"limitToPartitionCount": "true"},
+			target:           &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10"},
+			expectedValue:    20,
+		},
+		{
+			testName:         "lag is not capped when limitToPartitionCount is not set",
+			latestOffsets:    map[int32]int64{0: 1000, 1: 1000},
+			committedOffsets: map[int32]int64{0: 0, 1: 0},
+			target:           &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10"},
+			expectedValue:    2000,
+		},
+		{
+			testName:         "limitToPartitionCount without a pod-average target is an error",
+			latestOffsets:    map[int32]int64{0: 1000, 1: 1000},
+			committedOffsets: map[int32]int64{0: 0, 1: 0},
+			config:           map[string]string{"limitToPartitionCount": "true"},
+			target:           &rrethyv1.TargetSec{Type: rrethyv1.ValueTargetType, Value: "10"},
+			expectedErr:      true,
+		},
+		{
+			testName:         "limitToPartitionCount without a target is an error",
+			latestOffsets:    map[int32]int64{0: 1000, 1: 1000},
+			committedOffsets: map[int32]int64{0: 0, 1: 0},
+			config:           map[string]string{"limitToPartitionCount": "true"},
+			expectedErr:      true,
+		},
+		{
+			testName:         "unknown lag type is an error",
+			latestOffsets:    map[int32]int64{0: 100},
+			committedOffsets: map[int32]int64{0: 0},
+			config:           map[string]string{"lagType": "average"},
+			target:           &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10"},
+			expectedErr:      true,
+		},
+		{
+			testName:         "sasl without credentials is an error",
+			latestOffsets:    map[int32]int64{0: 100},
+			committedOffsets: map[int32]int64{0: 0},
+			config:           map[string]string{"sasl": "plain"},
+			target:           &rrethyv1.TargetSec{Type: rrethyv1.PodAverageTargetType, Value: "10"},
+			expectedErr:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			broker := newMockBroker(t, test.latestOffsets, test.committedOffsets)
+			defer broker.Close()
+
+			config := map[string]string{
+				"brokers":       broker.Addr(),
+				"topic":         testTopic,
+				"consumerGroup": testConsumerGroup,
+			}
+			for key, value := range test.config {
+				config[key] = value
+			}
+
+			value, err := NewClient().GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.KafkaMetricType, Config: config, Target: test.target})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		testName    string
+		config      map[string]string
+		expectedErr bool
+	}{
+		{
+			testName: "valid config",
+			config:   map[string]string{"brokers": "a:9092, b:9092", "topic": testTopic, "consumerGroup": testConsumerGroup},
+		},
+		{
+			testName:    "missing brokers",
+			config:      map[string]string{"topic": testTopic, "consumerGroup": testConsumerGroup},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing topic",
+			config:      map[string]string{"brokers": "a:9092", "consumerGroup": testConsumerGroup},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing consumer group",
+			config:      map[string]string{"brokers": "a:9092", "topic": testTopic},
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid boolean",
+			config:      map[string]string{"brokers": "a:9092", "topic": testTopic, "consumerGroup": testConsumerGroup, "tls": "yes please"},
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid version",
+			config:      map[string]string{"brokers": "a:9092", "topic": testTopic, "consumerGroup": testConsumerGroup, "version": "latest"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			_, err := parseConfig(test.config)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
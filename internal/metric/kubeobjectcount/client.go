@@ -0,0 +1,108 @@
+package kubeobjectcount
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+// listTimeout bounds how long a list waits for the cache to sync.
+// Listing a kind for the first time through a cached reader starts an informer, which never syncs if the controller
+// is not allowed to list and watch the kind.
+const listTimeout = 10 * time.Second
+
+// Client is a metric client which counts Kubernetes objects in the namespace of the scaler.
+type Client struct {
+	reader client.Reader
+}
+
+// NewClient returns a Client which lists objects as unstructured with reader.
+// This should be the manager's client with unstructured caching enabled, so each kind is watched by an informer and
+// listed from its cache. Otherwise every call lists the objects from the API server.
+func NewClient(reader client.Reader) *Client {
+	return &Client{reader: reader}
+}
+
+// GetValue returns the number of objects of the configured kind that match the selectors.
+//
+// The kind is configured with the "apiVersion" and "kind" keys.
+// Objects can be filtered with the "labelSelector" key, e.g. "app=dispatcher,tier!=canary",
+// and the "fieldSelector" key which matches arbitrary fields of the object, e.g. "status.phase=Pending".
+// The controller must be allowed to list and watch the kind.
+func (c *Client) GetValue(ctx context.Context, namespace string, metric rrethyv1.MetricSpec) (float64, error) {
+	gv, err := schema.ParseGroupVersion(metric.Config["apiVersion"])
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing apiVersion %s: %w", metric.Config["apiVersion"], err)
+	}
+	if gv.Version == "" {
+		return 0, errors.New("apiVersion is required")
+	}
+	kind := metric.Config["kind"]
+	if kind == "" {
+		return 0, errors.New("kind is required")
+	}
+
+	labelSelector, err := labels.Parse(metric.Config["labelSelector"])
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing labelSelector %s: %w", metric.Config["labelSelector"], err)
+	}
+	fieldSelector, err := fields.ParseSelector(metric.Config["fieldSelector"])
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing fieldSelector %s: %w", metric.Config["fieldSelector"], err)
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gv.WithKind(kind + "List"))
+
+	ctx, cancel := context.WithTimeout(ctx, listTimeout)
+	defer cancel()
+	err = c.reader.List(ctx, list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: labelSelector})
+	if err != nil {
+		return 0, fmt.Errorf("listing %s: %w", kind, err)
+	}
+
+	var count int
+	for _, item := range list.Items {
+		if matchesFields(item.Object, fieldSelector) {
+			count++
+		}
+	}
+	return float64(count), nil
+}
+
+// matchesFields returns true if the object matches every requirement of the field selector.
+// Fields are dot separated paths into the object, and missing fields are treated as empty strings.
+func matchesFields(object map[string]any, fieldSelector fields.Selector) bool {
+	for _, requirement := range fieldSelector.Requirements() {
+		value, found, err := unstructured.NestedFieldNoCopy(object, strings.Split(requirement.Field, ".")...)
+		actual := ""
+		if found && err == nil && value != nil {
+			actual = fmt.Sprint(value)
+		}
+
+		switch requirement.Operator {
+		case selection.Equals, selection.DoubleEquals:
+			if actual != requirement.Value {
+				return false
+			}
+		case selection.NotEquals:
+			if actual == requirement.Value {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package kubeobjectcount
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+func newPod(name, namespace string, labels map[string]string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestClient_GetValue(t *testing.T) {
+	objects := []client.Object{
+		newPod("pending-dispatcher", "default", map[string]string{"app": "dispatcher"}, corev1.PodPending),
+		newPod("running-dispatcher", "default", map[string]string{"app": "dispatcher"}, corev1.PodRunning),
+		newPod("pending-canary", "default", map[string]string{"app": "dispatcher", "track": "canary"}, corev1.PodPending),
+		newPod("pending-other", "default", map[string]string{"app": "other"}, corev1.PodPending),
+		newPod("pending-other-namespace", "other", map[string]string{"app": "dispatcher"}, corev1.PodPending),
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "active-job", Namespace: "default"}, Status: batchv1.JobStatus{Active: 1}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "finished-job", Namespace: "default"}, Status: batchv1.JobStatus{Succeeded: 1}},
+	}
+
+	tests := []struct {
+		testName      string
+		config        map[string]string
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{
+			testName:      "counts every object of the kind in the namespace",
+			config:        map[string]string{"apiVersion": "v1", "kind": "Pod"},
+			expectedValue: 4,
+		},
+		{
+			testName:      "filters by label selector",
+			config:        map[string]string{"apiVersion": "v1", "kind": "Pod", "labelSelector": "app=dispatcher,track!=canary"},
+			expectedValue: 2,
+		},
+		{
+			testName:      "filters by field selector",
+			config:        map[string]string{"apiVersion": "v1", "kind": "Pod", "labelSelector": "app=dispatcher", "fieldSelector": "status.phase=Pending"},
+			expectedValue: 2,
+		},
+		{
+			testName:      "field selector treats missing fields as empty",
+			config:        map[string]string{"apiVersion": "batch/v1", "kind": "Job", "fieldSelector": "status.active!="},
+			expectedValue: 1,
+		},
+		{
+			testName:    "missing kind is an error",
+			config:      map[string]string{"apiVersion": "v1"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing apiVersion is an error",
+			config:      map[string]string{"kind": "Pod"},
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid label selector is an error",
+			config:      map[string]string{"apiVersion": "v1", "kind": "Pod", "labelSelector": "app in ("},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			reader := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objects...).Build()
+			value, err := NewClient(reader).GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.KubeObjectCountMetricType, Config: test.config})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+const (
+	// LastAggregation uses the latest value of each series.
+	LastAggregation = "last"
+	// AverageAggregation uses the average value of each series over the window.
+	AverageAggregation = "avg"
+	// RateAggregation uses the per second rate of increase of each series over the window.
+	RateAggregation = "rate"
+
+	defaultWindow = time.Minute
+)
+
+// Client is a metric client which reads the series received by a Receiver from a Store.
+type Client struct {
+	store *Store
+}
+
+func NewClient(store *Store) *Client {
+	return &Client{store: store}
+}
+
+// GetValue returns the aggregation of the series with the metric name whose attributes match the selector.
+// When multiple series match, the aggregation of each series is summed.
+//
+// The series are selected with the "metricName" key and the "attributeSelector" key which
+// is a label selector over the resource and data point attributes, e.g. "service.name=checkout".
+// The aggregation is configured with the "aggregation" key which is one of last, avg or rate,
+// and defaults to last. The avg and rate aggregations use the "window" key which defaults to 1m.
+func (c *Client) GetValue(_ context.Context, _ string, metric rrethyv1.MetricSpec) (float64, error) {
+	metricName := metric.Config["metricName"]
+	if metricName == "" {
+		return 0, errors.New("metricName is required")
+	}
+
+	selector, err := labels.Parse(metric.Config["attributeSelector"])
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing attributeSelector %s: %w", metric.Config["attributeSelector"], err)
+	}
+
+	aggregation := metric.Config["aggregation"]
+	if aggregation == "" {
+		aggregation = LastAggregation
+	}
+	if aggregation != LastAggregation && aggregation != AverageAggregation && aggregation != RateAggregation {
+		return 0, fmt.Errorf("unknown aggregation %s", aggregation)
+	}
+
+	window := defaultWindow
+	if w, ok := metric.Config["window"]; ok {
+		window, err = time.ParseDuration(w)
+		if err != nil {
+			return 0, fmt.Errorf("failed parsing window %s: %w", w, err)
+		}
+		if window <= 0 {
+			return 0, fmt.Errorf("window %s must be positive", w)
+		}
+	}
+
+	since := c.store.Clock.Now().Add(-window)
+	var value float64
+	var found bool
+	for _, series := range c.store.Select(metricName, selector) {
+		var seriesValue float64
+		var ok bool
+		switch aggregation {
+		case LastAggregation:
+			seriesValue, ok = series.Points[len(series.Points)-1].Value, true
+		case AverageAggregation:
+			seriesValue, ok = average(dropBefore(series.Points, since))
+		case RateAggregation:
+			seriesValue, ok = rate(dropBefore(series.Points, since), series.Delta, window)
+		}
+		if ok {
+			value += seriesValue
+			found = true
+		}
+	}
+
+	if !found {
+		return 0, fmt.Errorf("no data for otlp metric %s", metricName)
+	}
+	return value, nil
+}
+
+// average returns the average value of the points.
+func average(points []Point) (float64, bool) {
+	if len(points) == 0 {
+		return 0, false
+	}
+	var sum float64
+	for _, point := range points {
+		sum += point.Value
+	}
+	return sum / float64(len(points)), true
+}
+
+// rate returns the per second rate of increase of the points.
+// Delta points are summed over the window, while cumulative points use the increase
+// between the first and last point, accounting for counter resets.
+func rate(points []Point, delta bool, window time.Duration) (float64, bool) {
+	if delta {
+		if len(points) == 0 {
+			return 0, false
+		}
+		var sum float64
+		for _, point := range points {
+			sum += point.Value
+		}
+		return sum / window.Seconds(), true
+	}
+
+	if len(points) < 2 {
+		return 0, false
+	}
+	var increase float64
+	for i := 1; i < len(points); i++ {
+		if points[i].Value < points[i-1].Value {
+			// The counter was reset so everything since the reset is an increase.
+			increase += points[i].Value
+		} else {
+			increase += points[i].Value - points[i-1].Value
+		}
+	}
+	elapsed := points[len(points)-1].Timestamp.Sub(points[0].Timestamp)
+	if elapsed <= 0 {
+		return 0, false
+	}
+	return increase / elapsed.Seconds(), true
+}
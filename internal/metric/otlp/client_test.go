@@ -0,0 +1,151 @@
+package otlp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+	clock "k8s.io/utils/clock/testing"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+var initialTime = time.Date(1997, time.November, 7, 0, 0, 0, 0, time.UTC)
+
+func TestClient_GetValue(t *testing.T) {
+	type seriesPoints struct {
+		name       string
+		attributes map[string]string
+		delta      bool
+		points     []Point
+	}
+
+	checkout := map[string]string{"service.name": "checkout", "pod": "a"}
+	checkoutB := map[string]string{"service.name": "checkout", "pod": "b"}
+	cart := map[string]string{"service.name": "cart", "pod": "c"}
+
+	tests := []struct {
+		testName      string
+		series        []seriesPoints
+		config        map[string]string
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{
+			testName: "last is the default aggregation",
+			series: []seriesPoints{
+				{name: "queue_depth", attributes: checkout, points: []Point{
+					{Timestamp: initialTime.Add(-20 * time.Second), Value: 5},
+					{Timestamp: initialTime.Add(-10 * time.Second), Value: 8},
+				}},
+			},
+			config:        map[string]string{"metricName": "queue_depth"},
+			expectedValue: 8,
+		},
+		{
+			testName: "matching series are summed",
+			series: []seriesPoints{
+				{name: "queue_depth", attributes: checkout, points: []Point{{Timestamp: initialTime, Value: 8}}},
+				{name: "queue_depth", attributes: checkoutB, points: []Point{{Timestamp: initialTime, Value: 2}}},
+				{name: "queue_depth", attributes: cart, points: []Point{{Timestamp: initialTime, Value: 100}}},
+			},
+			config:        map[string]string{"metricName": "queue_depth", "attributeSelector": "service.name=checkout"},
+			expectedValue: 10,
+		},
+		{
+			testName: "avg only uses points in the window",
+			series: []seriesPoints{
+				{name: "cpu", attributes: checkout, points: []Point{
+					{Timestamp: initialTime.Add(-2 * time.Minute), Value: 100},
+					{Timestamp: initialTime.Add(-40 * time.Second), Value: 2},
+					{Timestamp: initialTime.Add(-20 * time.Second), Value: 4},
+				}},
+			},
+			config:        map[string]string{"metricName": "cpu", "aggregation": "avg", "window": "1m"},
+			expectedValue: 3,
+		},
+		{
+			testName: "rate of a cumulative sum accounts for resets",
+			series: []seriesPoints{
+				{name: "requests", attributes: checkout, points: []Point{
+					{Timestamp: initialTime.Add(-30 * time.Second), Value: 100},
+					{Timestamp: initialTime.Add(-20 * time.Second), Value: 200},
+					{Timestamp: initialTime.Add(-10 * time.Second), Value: 50},
+				}},
+			},
+			config:        map[string]string{"metricName": "requests", "aggregation": "rate"},
+			expectedValue: 7.5,
+		},
+		{
+			testName: "rate of a delta sum is the sum over the window",
+			series: []seriesPoints{
+				{name: "requests", attributes: checkout, delta: true, points: []Point{
+					{Timestamp: initialTime.Add(-30 * time.Second), Value: 60},
+					{Timestamp: initialTime.Add(-10 * time.Second), Value: 60},
+				}},
+			},
+			config:        map[string]string{"metricName": "requests", "aggregation": "rate", "window": "1m"},
+			expectedValue: 2,
+		},
+		{
+			testName: "no matching series is an error",
+			series: []seriesPoints{
+				{name: "queue_depth", attributes: cart, points: []Point{{Timestamp: initialTime, Value: 8}}},
+			},
+			config:      map[string]string{"metricName": "queue_depth", "attributeSelector": "service.name=checkout"},
+			expectedErr: true,
+		},
+		{
+			testName: "points outside the retention are ignored",
+			series: []seriesPoints{
+				{name: "queue_depth", attributes: checkout, points: []Point{{Timestamp: initialTime.Add(-time.Hour), Value: 8}}},
+			},
+			config:      map[string]string{"metricName": "queue_depth"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing metric name is an error",
+			config:      map[string]string{},
+			expectedErr: true,
+		},
+		{
+			testName:    "unknown aggregation is an error",
+			config:      map[string]string{"metricName": "queue_depth", "aggregation": "p99"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			store := NewStore(10*time.Minute, WithClock(clock.NewFakePassiveClock(initialTime)))
+			for _, series := range test.series {
+				for _, point := range series.points {
+					store.Add(series.name, series.attributes, series.delta, point)
+				}
+			}
+
+			value, err := NewClient(store).GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.OTLPMetricType, Config: test.config})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	fakeClock := clock.NewFakePassiveClock(initialTime)
+	store := NewStore(time.Minute, WithClock(fakeClock))
+	store.Add("queue_depth", map[string]string{"pod": "a"}, false, Point{Timestamp: initialTime, Value: 1})
+	store.Add("queue_depth", map[string]string{"pod": "b"}, false, Point{Timestamp: initialTime.Add(30 * time.Second), Value: 1})
+
+	fakeClock.SetTime(initialTime.Add(75 * time.Second))
+	store.Prune()
+
+	assert.Len(t, store.Series, 1)
+	assert.Len(t, store.Select("queue_depth", labels.Everything()), 1)
+}
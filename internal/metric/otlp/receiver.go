@@ -0,0 +1,245 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	collectormetricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// MetricsPath is the path OTLP/HTTP metrics are exported to.
+	MetricsPath = "/v1/metrics"
+
+	protobufContentType = "application/x-protobuf"
+	jsonContentType     = "application/json"
+	maxRequestBytes     = 16 << 20
+	shutdownTimeout     = 5 * time.Second
+)
+
+var (
+	_ manager.Runnable                        = &Receiver{}
+	_ manager.LeaderElectionRunnable          = &Receiver{}
+	_ collectormetricsv1.MetricsServiceServer = &Receiver{}
+	_ http.Handler                            = &Receiver{}
+)
+
+// Receiver receives OTLP metrics over HTTP and gRPC and adds the gauges and sums to a Store.
+// Histograms and summaries are ignored.
+// The store is in memory and only read by the leader, so the receiver only runs on the leader and the manager must run
+// as a single replica for exporters to reach it.
+type Receiver struct {
+	collectormetricsv1.UnimplementedMetricsServiceServer
+
+	store    *Store
+	httpAddr string
+	grpcAddr string
+}
+
+// NewReceiver returns a Receiver which listens for OTLP/HTTP on httpAddr and OTLP/gRPC on grpcAddr.
+// An empty or "0" address disables the respective protocol.
+func NewReceiver(store *Store, httpAddr, grpcAddr string) *Receiver {
+	return &Receiver{store: store, httpAddr: httpAddr, grpcAddr: grpcAddr}
+}
+
+// NeedLeaderElection returns true so only the leader, which reconciles the scalers, receives metrics.
+// Otherwise exporters behind a Service would spread data points across replicas and the leader would scale on part of them.
+func (r *Receiver) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the enabled servers until the context is cancelled and periodically prunes the store.
+func (r *Receiver) Start(ctx context.Context) error {
+	log := log.FromContext(ctx).WithName("otlp-receiver")
+	errs := make(chan error, 2)
+
+	if enabled(r.httpAddr) {
+		listener, err := net.Listen("tcp", r.httpAddr)
+		if err != nil {
+			return fmt.Errorf("listening for otlp/http on %s: %w", r.httpAddr, err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle(MetricsPath, r)
+		server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+		go func() {
+			log.Info("serving otlp/http", "address", listener.Addr().String())
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("serving otlp/http: %w", err)
+			}
+		}()
+	}
+
+	if enabled(r.grpcAddr) {
+		listener, err := net.Listen("tcp", r.grpcAddr)
+		if err != nil {
+			return fmt.Errorf("listening for otlp/grpc on %s: %w", r.grpcAddr, err)
+		}
+		server := grpc.NewServer()
+		collectormetricsv1.RegisterMetricsServiceServer(server, r)
+		go func() {
+			<-ctx.Done()
+			server.GracefulStop()
+		}()
+		go func() {
+			log.Info("serving otlp/grpc", "address", listener.Addr().String())
+			if err := server.Serve(listener); err != nil {
+				errs <- fmt.Errorf("serving otlp/grpc: %w", err)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(r.store.Retention)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case <-ticker.C:
+			r.store.Prune()
+		}
+	}
+}
+
+// Export implements the OTLP/gRPC metrics service.
+func (r *Receiver) Export(_ context.Context, req *collectormetricsv1.ExportMetricsServiceRequest) (*collectormetricsv1.ExportMetricsServiceResponse, error) {
+	r.add(req)
+	return &collectormetricsv1.ExportMetricsServiceResponse{}, nil
+}
+
+// ServeHTTP implements OTLP/HTTP for metrics with both protobuf and JSON encodings.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var (
+		marshal   func(proto.Message) ([]byte, error)
+		unmarshal func([]byte, proto.Message) error
+	)
+	// Clients may add parameters to the content type, e.g. "application/json; charset=utf-8".
+	contentType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid content type: %s", err), http.StatusUnsupportedMediaType)
+		return
+	}
+	switch contentType {
+	case protobufContentType:
+		marshal, unmarshal = proto.Marshal, proto.Unmarshal
+	case jsonContentType:
+		marshal, unmarshal = protojson.Marshal, protojson.Unmarshal
+	default:
+		http.Error(w, fmt.Sprintf("unsupported content type %s", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	exportRequest := &collectormetricsv1.ExportMetricsServiceRequest{}
+	if err := unmarshal(body, exportRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.add(exportRequest)
+
+	resp, err := marshal(&collectormetricsv1.ExportMetricsServiceResponse{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(resp)
+}
+
+// add adds every gauge and sum data point of the request to the store.
+func (r *Receiver) add(req *collectormetricsv1.ExportMetricsServiceRequest) {
+	for _, resourceMetrics := range req.GetResourceMetrics() {
+		resourceAttributes := toAttributes(nil, resourceMetrics.GetResource().GetAttributes())
+		for _, scopeMetrics := range resourceMetrics.GetScopeMetrics() {
+			for _, m := range scopeMetrics.GetMetrics() {
+				switch data := m.GetData().(type) {
+				case *metricsv1.Metric_Gauge:
+					r.addDataPoints(m.GetName(), resourceAttributes, false, data.Gauge.GetDataPoints())
+				case *metricsv1.Metric_Sum:
+					delta := data.Sum.GetAggregationTemporality() == metricsv1.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+					r.addDataPoints(m.GetName(), resourceAttributes, delta, data.Sum.GetDataPoints())
+				}
+			}
+		}
+	}
+}
+
+func (r *Receiver) addDataPoints(name string, resourceAttributes map[string]string, delta bool, dataPoints []*metricsv1.NumberDataPoint) {
+	for _, dataPoint := range dataPoints {
+		var value float64
+		switch v := dataPoint.GetValue().(type) {
+		case *metricsv1.NumberDataPoint_AsDouble:
+			value = v.AsDouble
+		case *metricsv1.NumberDataPoint_AsInt:
+			value = float64(v.AsInt)
+		default:
+			continue
+		}
+
+		timestamp := r.store.Clock.Now()
+		if dataPoint.GetTimeUnixNano() != 0 {
+			timestamp = time.Unix(0, int64(dataPoint.GetTimeUnixNano()))
+		}
+
+		attributes := toAttributes(resourceAttributes, dataPoint.GetAttributes())
+		r.store.Add(name, attributes, delta, Point{Timestamp: timestamp, Value: value})
+	}
+}
+
+// toAttributes returns a copy of base with the key values added, converting values to strings.
+func toAttributes(base map[string]string, keyValues []*commonv1.KeyValue) map[string]string {
+	attributes := make(map[string]string, len(base)+len(keyValues))
+	for key, value := range base {
+		attributes[key] = value
+	}
+	for _, keyValue := range keyValues {
+		value := keyValue.GetValue()
+		switch v := value.GetValue().(type) {
+		case *commonv1.AnyValue_StringValue:
+			attributes[keyValue.GetKey()] = v.StringValue
+		case *commonv1.AnyValue_BoolValue:
+			attributes[keyValue.GetKey()] = strconv.FormatBool(v.BoolValue)
+		case *commonv1.AnyValue_IntValue:
+			attributes[keyValue.GetKey()] = strconv.FormatInt(v.IntValue, 10)
+		case *commonv1.AnyValue_DoubleValue:
+			attributes[keyValue.GetKey()] = strconv.FormatFloat(v.DoubleValue, 'f', -1, 64)
+		}
+	}
+	return attributes
+}
+
+// enabled returns true if the address is set to something other than "0", matching the manager's flags.
+func enabled(addr string) bool {
+	return addr != "" && addr != "0"
+}
@@ -0,0 +1,150 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	collectormetricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcev1 "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/apimachinery/pkg/labels"
+	clock "k8s.io/utils/clock/testing"
+)
+
+func stringAttribute(key, value string) *commonv1.KeyValue {
+	return &commonv1.KeyValue{Key: key, Value: &commonv1.AnyValue{Value: &commonv1.AnyValue_StringValue{StringValue: value}}}
+}
+
+func newExportRequest() *collectormetricsv1.ExportMetricsServiceRequest {
+	timestamp := uint64(initialTime.UnixNano())
+	return &collectormetricsv1.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricsv1.ResourceMetrics{{
+			Resource: &resourcev1.Resource{Attributes: []*commonv1.KeyValue{stringAttribute("service.name", "checkout")}},
+			ScopeMetrics: []*metricsv1.ScopeMetrics{{
+				Metrics: []*metricsv1.Metric{
+					{
+						Name: "queue_depth",
+						Data: &metricsv1.Metric_Gauge{Gauge: &metricsv1.Gauge{DataPoints: []*metricsv1.NumberDataPoint{
+							{TimeUnixNano: timestamp, Attributes: []*commonv1.KeyValue{stringAttribute("queue", "orders")}, Value: &metricsv1.NumberDataPoint_AsInt{AsInt: 12}},
+						}}},
+					},
+					{
+						Name: "requests",
+						Data: &metricsv1.Metric_Sum{Sum: &metricsv1.Sum{
+							AggregationTemporality: metricsv1.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+							DataPoints:             []*metricsv1.NumberDataPoint{{TimeUnixNano: timestamp, Value: &metricsv1.NumberDataPoint_AsDouble{AsDouble: 3.5}}},
+						}},
+					},
+					{
+						Name: "latency",
+						Data: &metricsv1.Metric_Histogram{Histogram: &metricsv1.Histogram{}},
+					},
+				},
+			}},
+		}},
+	}
+}
+
+func assertPoint(t *testing.T, expected Point, points []Point) {
+	if assert.Len(t, points, 1) {
+		assert.True(t, expected.Timestamp.Equal(points[0].Timestamp))
+		assert.Equal(t, expected.Value, points[0].Value)
+	}
+}
+
+func assertStored(t *testing.T, store *Store) {
+	queueDepth := store.Select("queue_depth", labels.Everything())
+	if assert.Len(t, queueDepth, 1) {
+		assert.Equal(t, map[string]string{"service.name": "checkout", "queue": "orders"}, queueDepth[0].Attributes)
+		assertPoint(t, Point{Timestamp: initialTime, Value: 12}, queueDepth[0].Points)
+		assert.False(t, queueDepth[0].Delta)
+	}
+
+	requests := store.Select("requests", labels.Everything())
+	if assert.Len(t, requests, 1) {
+		assertPoint(t, Point{Timestamp: initialTime, Value: 3.5}, requests[0].Points)
+		assert.True(t, requests[0].Delta)
+	}
+
+	assert.Empty(t, store.Select("latency", labels.Everything()))
+}
+
+func TestReceiver_Export(t *testing.T) {
+	store := NewStore(10*time.Minute, WithClock(clock.NewFakePassiveClock(initialTime)))
+	_, err := NewReceiver(store, "", "").Export(context.Background(), newExportRequest())
+	assert.NoError(t, err)
+	assertStored(t, store)
+}
+
+func TestReceiver_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		testName            string
+		contentType         string
+		marshal             func(proto.Message) ([]byte, error)
+		expectedStatus      int
+		expectedContentType string
+	}{
+		{
+			testName:            "protobuf encoding",
+			contentType:         "application/x-protobuf",
+			marshal:             proto.Marshal,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-protobuf",
+		},
+		{
+			testName:            "json encoding",
+			contentType:         "application/json",
+			marshal:             protojson.Marshal,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+		},
+		{
+			testName:            "json encoding with parameters",
+			contentType:         "application/json; charset=utf-8",
+			marshal:             protojson.Marshal,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+		},
+		{
+			testName:       "unsupported encoding",
+			contentType:    "text/plain",
+			marshal:        protojson.Marshal,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			testName:       "invalid content type",
+			contentType:    "application/json; charset",
+			marshal:        protojson.Marshal,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			store := NewStore(10*time.Minute, WithClock(clock.NewFakePassiveClock(initialTime)))
+			server := httptest.NewServer(NewReceiver(store, "", ""))
+			defer server.Close()
+
+			body, err := test.marshal(newExportRequest())
+			assert.NoError(t, err)
+
+			resp, err := http.Post(server.URL+MetricsPath, test.contentType, bytes.NewReader(body))
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			if test.expectedStatus == http.StatusOK {
+				assert.Equal(t, test.expectedContentType, resp.Header.Get("Content-Type"))
+				assertStored(t, store)
+			}
+		})
+	}
+}
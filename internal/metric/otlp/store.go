@@ -0,0 +1,163 @@
+package otlp
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/clock"
+)
+
+// Point is a single value of a series at a point in time.
+type Point struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// Series is a time series identified by its metric name and attributes.
+type Series struct {
+	// Name is the name of the metric.
+	Name string
+	// Attributes are the resource and data point attributes of the series.
+	Attributes map[string]string
+	// Delta is true if each point is the change since the previous point rather than a cumulative value.
+	Delta bool
+	// Points are the points of the series within the retention, ordered by timestamp.
+	Points []Point
+}
+
+// StoreOption is a function that configures a Store.
+type StoreOption func(*Store)
+
+// WithClock sets the clock used by the store.
+// This is useful for testing where a fake clock is used.
+func WithClock(clock clock.PassiveClock) StoreOption {
+	return func(s *Store) {
+		s.Clock = clock
+	}
+}
+
+// Store is a thread-safe in-memory store of time series which only keeps points within the retention.
+type Store struct {
+	// Clock is used to get the current time.
+	// It is mocked in tests.
+	Clock clock.PassiveClock
+	// Retention is how long points are kept.
+	Retention time.Duration
+	// Mutex is used to synchronize access to Series.
+	Mutex sync.RWMutex
+	// Series is a map of series keys to the series.
+	Series map[string]*Series
+}
+
+// NewStore creates a new Store which keeps points for the retention duration.
+func NewStore(retention time.Duration, options ...StoreOption) *Store {
+	s := &Store{
+		Clock:     clock.RealClock{},
+		Retention: retention,
+		Series:    make(map[string]*Series),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Add adds a point to the series with the name and attributes, creating the series if needed.
+// Points older than the retention are dropped.
+func (s *Store) Add(name string, attributes map[string]string, delta bool, point Point) {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+
+	if point.Timestamp.Before(s.cutoff()) {
+		return
+	}
+
+	key := seriesKey(name, attributes)
+	series, ok := s.Series[key]
+	if !ok {
+		series = &Series{Name: name, Attributes: attributes}
+		s.Series[key] = series
+	}
+	series.Delta = delta
+
+	// Points almost always arrive in order so this is usually an append.
+	i := sort.Search(len(series.Points), func(i int) bool { return series.Points[i].Timestamp.After(point.Timestamp) })
+	series.Points = slices.Insert(series.Points, i, point)
+	series.Points = dropBefore(series.Points, s.cutoff())
+}
+
+// Select returns a copy of every series with the name whose attributes match the selector.
+// Only points within the retention are returned.
+func (s *Store) Select(name string, selector labels.Selector) []Series {
+	s.Mutex.RLock()
+	defer s.Mutex.RUnlock()
+
+	cutoff := s.cutoff()
+	var selected []Series
+	for _, series := range s.Series {
+		if series.Name != name || !selector.Matches(labels.Set(series.Attributes)) {
+			continue
+		}
+		points := dropBefore(series.Points, cutoff)
+		if len(points) == 0 {
+			continue
+		}
+		selected = append(selected, Series{
+			Name:       series.Name,
+			Attributes: series.Attributes,
+			Delta:      series.Delta,
+			Points:     slices.Clone(points),
+		})
+	}
+	return selected
+}
+
+// Prune removes points older than the retention and series without any points.
+func (s *Store) Prune() {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+
+	cutoff := s.cutoff()
+	for key, series := range s.Series {
+		series.Points = dropBefore(series.Points, cutoff)
+		if len(series.Points) == 0 {
+			delete(s.Series, key)
+		}
+	}
+}
+
+// cutoff returns the time before which points are dropped.
+func (s *Store) cutoff() time.Time {
+	return s.Clock.Now().Add(-s.Retention)
+}
+
+// dropBefore returns the points at or after the cutoff.
+func dropBefore(points []Point, cutoff time.Time) []Point {
+	i := sort.Search(len(points), func(i int) bool { return !points[i].Timestamp.Before(cutoff) })
+	return points[i:]
+}
+
+// seriesKey returns a key which uniquely identifies a series by its name and attributes.
+func seriesKey(name string, attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, key := range keys {
+		b.WriteString("\x00")
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(attributes[key])
+	}
+	return b.String()
+}
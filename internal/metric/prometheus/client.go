@@ -1,8 +1,14 @@
 package prometheus
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	api "github.com/prometheus/client_golang/api"
 	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
 
 	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
 )
@@ -25,6 +31,38 @@ func NewClient() *Client {
 	}
 }
 
-func (c *Client) GetValue(metric rrethyv1.MetricSpec) (float64, error) {
-	return 0, nil
+// GetValue returns the result of the instant query in the "query" key, summing the samples of a vector result.
+// The query is sent to the "serverAddress" key if set, and to the default Prometheus otherwise.
+func (c *Client) GetValue(ctx context.Context, _ string, metric rrethyv1.MetricSpec) (float64, error) {
+	query := metric.Config["query"]
+	if query == "" {
+		return 0, errors.New("query is required")
+	}
+
+	promAPI := c.DefaultApi
+	if address := metric.Config["serverAddress"]; address != "" {
+		promClient, err := api.NewClient(api.Config{Address: address})
+		if err != nil {
+			return 0, fmt.Errorf("creating client for %s: %w", address, err)
+		}
+		promAPI = prometheusv1.NewAPI(promClient)
+	}
+
+	result, _, err := promAPI.Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("querying prometheus: %w", err)
+	}
+
+	switch result := result.(type) {
+	case *model.Scalar:
+		return float64(result.Value), nil
+	case model.Vector:
+		var sum float64
+		for _, sample := range result {
+			sum += float64(sample.Value)
+		}
+		return sum, nil
+	default:
+		return 0, fmt.Errorf("unsupported result type %s, the query must return a scalar or vector", result.Type())
+	}
 }
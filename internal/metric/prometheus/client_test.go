@@ -0,0 +1,95 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+// newFakeQueryAPI returns a fake Prometheus query API which serves the data of the response for the query.
+func newFakeQueryAPI(t *testing.T, responses map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.NoError(t, r.ParseForm())
+		data, ok := responses[r.Form.Get("query")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unknown query"}`))
+			assert.NoError(t, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`{"status":"success","data":` + data + `}`))
+		assert.NoError(t, err)
+	}))
+}
+
+func TestClient_GetValue(t *testing.T) {
+	server := newFakeQueryAPI(t, map[string]string{
+		"scalar(up)": `{"resultType":"scalar","result":[1700000000,"3"]}`,
+		"container_cpu_usage": `{"resultType":"vector","result":[` +
+			`{"metric":{"pod":"web-1"},"value":[1700000000,"0.25"]},` +
+			`{"metric":{"pod":"web-2"},"value":[1700000000,"0.75"]}]}`,
+		"absent_metric": `{"resultType":"vector","result":[]}`,
+		"range[5m]":     `{"resultType":"matrix","result":[]}`,
+	})
+	defer server.Close()
+
+	tests := []struct {
+		testName      string
+		config        map[string]string
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{
+			testName:      "scalar result",
+			config:        map[string]string{"serverAddress": server.URL, "query": "scalar(up)"},
+			expectedValue: 3,
+		},
+		{
+			testName:      "sums the samples of a vector result",
+			config:        map[string]string{"serverAddress": server.URL, "query": "container_cpu_usage"},
+			expectedValue: 1,
+		},
+		{
+			testName:      "empty vector result",
+			config:        map[string]string{"serverAddress": server.URL, "query": "absent_metric"},
+			expectedValue: 0,
+		},
+		{
+			testName:    "matrix result",
+			config:      map[string]string{"serverAddress": server.URL, "query": "range[5m]"},
+			expectedErr: true,
+		},
+		{
+			testName:    "query error",
+			config:      map[string]string{"serverAddress": server.URL, "query": "unknown"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing query",
+			config:      map[string]string{"serverAddress": server.URL},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			value, err := NewClient().GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.PrometheusMetricType, Config: test.config})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+const (
+	// MessagesReadyMode uses the number of messages ready to be delivered to consumers.
+	MessagesReadyMode = "messages-ready"
+	// PublishRateMode uses the rate of messages published to the queue per second.
+	PublishRateMode = "publish-rate"
+
+	defaultVhost   = "/"
+	defaultTimeout = 10 * time.Second
+)
+
+// queue is the subset of the management API queue response that is used.
+type queue struct {
+	MessagesReady float64 `json:"messages_ready"`
+	MessageStats  struct {
+		PublishDetails struct {
+			Rate float64 `json:"rate"`
+		} `json:"publish_details"`
+	} `json:"message_stats"`
+}
+
+// Client is a metric client which queries the RabbitMQ management HTTP API.
+type Client struct {
+	httpClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: defaultTimeout}}
+}
+
+// GetValue returns either the number of ready messages or the publish rate of the queue.
+func (c *Client) GetValue(ctx context.Context, _ string, metric rrethyv1.MetricSpec) (float64, error) {
+	host := metric.Config["host"]
+	if host == "" {
+		return 0, errors.New("host is required")
+	}
+	queueName := metric.Config["queue"]
+	if queueName == "" {
+		return 0, errors.New("queue is required")
+	}
+	vhost := metric.Config["vhost"]
+	if vhost == "" {
+		vhost = defaultVhost
+	}
+	mode := metric.Config["mode"]
+	if mode == "" {
+		mode = MessagesReadyMode
+	}
+	if mode != MessagesReadyMode && mode != PublishRateMode {
+		return 0, fmt.Errorf("unknown mode %s", mode)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/queues/%s/%s", strings.TrimSuffix(host, "/"), url.PathEscape(vhost), url.PathEscape(queueName))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("creating request: %w", err)
+	}
+	if username := metric.Config["username"]; username != "" {
+		req.SetBasicAuth(username, metric.Config["password"])
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("querying rabbitmq management api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("querying rabbitmq management api: unexpected status %s", resp.Status)
+	}
+
+	var q queue
+	if err := json.NewDecoder(resp.Body).Decode(&q); err != nil {
+		return 0, fmt.Errorf("decoding rabbitmq management api response: %w", err)
+	}
+
+	switch mode {
+	case PublishRateMode:
+		return q.MessageStats.PublishDetails.Rate, nil
+	default:
+		return q.MessagesReady, nil
+	}
+}
@@ -0,0 +1,95 @@
+package rabbitmq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+// newFakeManagementAPI returns a fake management API serving a single queue in the given vhost.
+func newFakeManagementAPI(t *testing.T, vhost, queueName, username, password string) *httptest.Server {
+	// The default vhost is "/" so the path has to be matched escaped.
+	expectedPath := "/api/queues/" + url.PathEscape(vhost) + "/" + url.PathEscape(queueName)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != username || pass != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.EscapedPath() != expectedPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte(`{"name":"` + queueName + `","messages_ready":42,"messages":50,"message_stats":{"publish_details":{"rate":12.5}}}`))
+		assert.NoError(t, err)
+	}))
+}
+
+func TestClient_GetValue(t *testing.T) {
+	tests := []struct {
+		testName      string
+		config        map[string]string
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{
+			testName:      "messages ready is the default mode",
+			config:        map[string]string{"queue": "jobs", "username": "guest", "password": "guest"},
+			expectedValue: 42,
+		},
+		{
+			testName:      "publish rate mode",
+			config:        map[string]string{"queue": "jobs", "mode": "publish-rate", "username": "guest", "password": "guest"},
+			expectedValue: 12.5,
+		},
+		{
+			testName:      "vhost is escaped",
+			config:        map[string]string{"queue": "jobs", "vhost": "team/a", "username": "guest", "password": "guest"},
+			expectedValue: 42,
+		},
+		{
+			testName:    "wrong credentials are an error",
+			config:      map[string]string{"queue": "jobs", "username": "guest", "password": "wrong"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing queue is an error",
+			config:      map[string]string{"queue": "missing", "username": "guest", "password": "guest"},
+			expectedErr: true,
+		},
+		{
+			testName:    "unknown mode is an error",
+			config:      map[string]string{"queue": "jobs", "mode": "messages-unacked", "username": "guest", "password": "guest"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			vhost := test.config["vhost"]
+			if vhost == "" {
+				vhost = "/"
+			}
+			server := newFakeManagementAPI(t, vhost, "jobs", "guest", "guest")
+			defer server.Close()
+
+			config := map[string]string{"host": server.URL}
+			for key, value := range test.config {
+				config[key] = value
+			}
+
+			value, err := NewClient().GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.RabbitMQMetricType, Config: config})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
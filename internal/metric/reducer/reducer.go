@@ -0,0 +1,64 @@
+package reducer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	// Last uses the latest value.
+	Last = "last"
+	// First uses the earliest value.
+	First = "first"
+	// Average uses the average of the values.
+	Average = "avg"
+	// Sum uses the sum of the values.
+	Sum = "sum"
+	// Min uses the smallest value.
+	Min = "min"
+	// Max uses the largest value.
+	Max = "max"
+)
+
+// Validate returns an error if the reducer is unknown.
+// An empty reducer is valid and is the same as Last.
+func Validate(reducer string) error {
+	switch reducer {
+	case "", Last, First, Average, Sum, Min, Max:
+		return nil
+	default:
+		return fmt.Errorf("unknown reducer %s", reducer)
+	}
+}
+
+// Reduce reduces the values, ordered from earliest to latest, to a single value.
+// An empty reducer defaults to Last.
+func Reduce(reducer string, values []float64) (float64, error) {
+	if err := Validate(reducer); err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, errors.New("no values to reduce")
+	}
+
+	switch reducer {
+	case First:
+		return values[0], nil
+	case Average, Sum:
+		var sum float64
+		for _, value := range values {
+			sum += value
+		}
+		if reducer == Average {
+			return sum / float64(len(values)), nil
+		}
+		return sum, nil
+	case Min:
+		return slices.Min(values), nil
+	case Max:
+		return slices.Max(values), nil
+	default:
+		return values[len(values)-1], nil
+	}
+}
@@ -0,0 +1,41 @@
+package reducer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReduce(t *testing.T) {
+	values := []float64{4, 1, 7, 2}
+
+	tests := []struct {
+		testName      string
+		reducer       string
+		values        []float64
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{testName: "last is the default", reducer: "", values: values, expectedValue: 2},
+		{testName: "last", reducer: Last, values: values, expectedValue: 2},
+		{testName: "first", reducer: First, values: values, expectedValue: 4},
+		{testName: "avg", reducer: Average, values: values, expectedValue: 3.5},
+		{testName: "sum", reducer: Sum, values: values, expectedValue: 14},
+		{testName: "min", reducer: Min, values: values, expectedValue: 1},
+		{testName: "max", reducer: Max, values: values, expectedValue: 7},
+		{testName: "no values is an error", reducer: Max, values: nil, expectedErr: true},
+		{testName: "unknown reducer is an error", reducer: "p99", values: values, expectedErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			value, err := Reduce(test.reducer, test.values)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
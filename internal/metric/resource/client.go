@@ -0,0 +1,79 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+// podMetricsListGVK is the kind of the list of pod metrics served by the resource metrics API, e.g. by metrics-server.
+var podMetricsListGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetricsList"}
+
+// Client is a metric client which sums the resource usage of pods from the resource metrics API.
+type Client struct {
+	reader client.Reader
+}
+
+// NewClient returns a Client which lists pod metrics with reader.
+// This should be the manager's API reader since the resource metrics API can't be watched.
+func NewClient(reader client.Reader) *Client {
+	return &Client{reader: reader}
+}
+
+// GetValue returns the total usage of the resource of the target by the containers of the pods matching the
+// "labelSelector" key, limited to the container of the target if it has one.
+// CPU is in cores and every other resource is in its base unit, e.g. bytes for memory, the same as the requests
+// utilization targets compare against.
+func (c *Client) GetValue(ctx context.Context, namespace string, metric rrethyv1.MetricSpec) (float64, error) {
+	if metric.Target == nil || metric.Target.Resource == "" {
+		return 0, errors.New("resource is required")
+	}
+	if metric.Config["labelSelector"] == "" {
+		return 0, errors.New("labelSelector is required")
+	}
+	labelSelector, err := labels.Parse(metric.Config["labelSelector"])
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing labelSelector %s: %w", metric.Config["labelSelector"], err)
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(podMetricsListGVK)
+	if err := c.reader.List(ctx, list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+		return 0, fmt.Errorf("listing pod metrics: %w", err)
+	}
+
+	var usage float64
+	for _, item := range list.Items {
+		containers, _, err := unstructured.NestedSlice(item.Object, "containers")
+		if err != nil {
+			return 0, fmt.Errorf("failed reading containers of pod metrics %s: %w", item.GetName(), err)
+		}
+		for _, container := range containers {
+			container, ok := container.(map[string]any)
+			if !ok {
+				continue
+			}
+			if metric.Target.Container != "" && container["name"] != metric.Target.Container {
+				continue
+			}
+			raw, found, err := unstructured.NestedString(container, "usage", string(metric.Target.Resource))
+			if err != nil || !found {
+				continue
+			}
+			quantity, err := k8sresource.ParseQuantity(raw)
+			if err != nil {
+				return 0, fmt.Errorf("failed parsing %s usage %s of pod %s: %w", metric.Target.Resource, raw, item.GetName(), err)
+			}
+			usage += quantity.AsApproximateFloat64()
+		}
+	}
+	return usage, nil
+}
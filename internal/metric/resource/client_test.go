@@ -0,0 +1,102 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+func newPodMetrics(name, namespace string, labels map[string]string, containers ...map[string]any) *unstructured.Unstructured {
+	items := make([]any, 0, len(containers))
+	for _, container := range containers {
+		items = append(items, container)
+	}
+	podMetrics := &unstructured.Unstructured{Object: map[string]any{"containers": items}}
+	podMetrics.SetGroupVersionKind(podMetricsListGVK.GroupVersion().WithKind("PodMetrics"))
+	podMetrics.SetName(name)
+	podMetrics.SetNamespace(namespace)
+	podMetrics.SetLabels(labels)
+	return podMetrics
+}
+
+func newContainer(name, cpu, memory string) map[string]any {
+	return map[string]any{"name": name, "usage": map[string]any{"cpu": cpu, "memory": memory}}
+}
+
+func TestClient_GetValue(t *testing.T) {
+	objects := []client.Object{
+		newPodMetrics("web-1", "default", map[string]string{"app": "web"}, newContainer("app", "250m", "128Mi"), newContainer("sidecar", "50m", "32Mi")),
+		newPodMetrics("web-2", "default", map[string]string{"app": "web"}, newContainer("app", "750m", "128Mi")),
+		newPodMetrics("other", "default", map[string]string{"app": "other"}, newContainer("app", "1", "1Gi")),
+		newPodMetrics("web-other-namespace", "other", map[string]string{"app": "web"}, newContainer("app", "1", "1Gi")),
+	}
+
+	tests := []struct {
+		testName      string
+		config        map[string]string
+		target        *rrethyv1.TargetSec
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{
+			testName:      "sums cpu usage of every container",
+			config:        map[string]string{"labelSelector": "app=web"},
+			target:        &rrethyv1.TargetSec{Type: rrethyv1.UtilizationTargetType, Value: "70", Resource: corev1.ResourceCPU},
+			expectedValue: 1.05,
+		},
+		{
+			testName:      "sums memory usage of the container",
+			config:        map[string]string{"labelSelector": "app=web"},
+			target:        &rrethyv1.TargetSec{Type: rrethyv1.UtilizationTargetType, Value: "70", Resource: corev1.ResourceMemory, Container: "app"},
+			expectedValue: 256 * 1024 * 1024,
+		},
+		{
+			testName:      "no matching pods",
+			config:        map[string]string{"labelSelector": "app=missing"},
+			target:        &rrethyv1.TargetSec{Type: rrethyv1.UtilizationTargetType, Value: "70", Resource: corev1.ResourceCPU},
+			expectedValue: 0,
+		},
+		{
+			testName:    "missing resource",
+			config:      map[string]string{"labelSelector": "app=web"},
+			target:      &rrethyv1.TargetSec{Type: rrethyv1.ValueTargetType, Value: "1"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing target",
+			config:      map[string]string{"labelSelector": "app=web"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing label selector",
+			target:      &rrethyv1.TargetSec{Type: rrethyv1.UtilizationTargetType, Value: "70", Resource: corev1.ResourceCPU},
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid label selector",
+			config:      map[string]string{"labelSelector": "app in (web"},
+			target:      &rrethyv1.TargetSec{Type: rrethyv1.UtilizationTargetType, Value: "70", Resource: corev1.ResourceCPU},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			client := NewClient(fake.NewClientBuilder().WithObjects(objects...).Build())
+			value, err := client.GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.ResourceMetricType, Config: test.config, Target: test.target})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, test.expectedValue, value, 1e-9)
+		})
+	}
+}
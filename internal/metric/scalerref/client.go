@@ -0,0 +1,120 @@
+package scalerref
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+const (
+	// DesiredReplicas uses the replicas the referenced scaler last decided on.
+	DesiredReplicas = "desired"
+	// CurrentReplicas uses the replicas of the referenced scaler's target when it was last reconciled.
+	CurrentReplicas = "current"
+)
+
+// Client is a metric client which reads the replicas of another HorizontalReplicaScaler in the same namespace.
+type Client struct {
+	reader client.Reader
+}
+
+// NewClient returns a Client which gets scalers with reader.
+// This should be the manager's client so scalers are read from its cache.
+func NewClient(reader client.Reader) *Client {
+	return &Client{reader: reader}
+}
+
+// GetValue returns the replicas of the scaler named by the "name" key multiplied by the "multiplier" key
+// and then added to the "offset" key, which default to 1 and 0 respectively.
+// The "replicas" key is one of desired or current, and defaults to desired.
+// An error is returned if following the scaler-ref metrics of the referenced scaler leads to a cycle.
+func (c *Client) GetValue(ctx context.Context, namespace string, metric rrethyv1.MetricSpec) (float64, error) {
+	name := metric.Config["name"]
+	if name == "" {
+		return 0, errors.New("name is required")
+	}
+	replicas := metric.Config["replicas"]
+	if replicas == "" {
+		replicas = DesiredReplicas
+	}
+	if replicas != DesiredReplicas && replicas != CurrentReplicas {
+		return 0, fmt.Errorf("replicas must be one of %s or %s", DesiredReplicas, CurrentReplicas)
+	}
+	multiplier, err := parseFloat(metric.Config, "multiplier", 1)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := parseFloat(metric.Config, "offset", 0)
+	if err != nil {
+		return 0, err
+	}
+
+	var scaler rrethyv1.HorizontalReplicaScaler
+	if err := c.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &scaler); err != nil {
+		return 0, fmt.Errorf("getting scaler %s: %w", name, err)
+	}
+	if err := c.detectCycle(ctx, namespace, []string{name}, &scaler); err != nil {
+		return 0, err
+	}
+
+	value := float64(scaler.Status.DesiredReplicas)
+	if replicas == CurrentReplicas {
+		value = float64(scaler.Status.CurrentReplicas)
+	}
+	return value*multiplier + offset, nil
+}
+
+// detectCycle follows the scaler-ref metrics of the scaler depth first and returns an error if a scaler
+// already in the path is referenced again. Scalers which don't exist end the path since they can't
+// reference anything.
+func (c *Client) detectCycle(ctx context.Context, namespace string, path []string, scaler *rrethyv1.HorizontalReplicaScaler) error {
+	for _, name := range ReferencedScalers(scaler) {
+		if slices.Contains(path, name) {
+			return fmt.Errorf("scaler-ref cycle detected: %s -> %s", strings.Join(path, " -> "), name)
+		}
+
+		var referenced rrethyv1.HorizontalReplicaScaler
+		err := c.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &referenced)
+		if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("getting scaler %s: %w", name, err)
+		} else if err != nil {
+			continue
+		}
+		if err := c.detectCycle(ctx, namespace, append(path, name), &referenced); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReferencedScalers returns the names of the scalers referenced by the scaler-ref metrics of the scaler.
+func ReferencedScalers(scaler *rrethyv1.HorizontalReplicaScaler) []string {
+	var names []string
+	for _, metric := range scaler.Spec.Metrics {
+		if metric.Type == rrethyv1.ScalerRefMetricType && metric.Config["name"] != "" && !slices.Contains(names, metric.Config["name"]) {
+			names = append(names, metric.Config["name"])
+		}
+	}
+	return names
+}
+
+// parseFloat parses the float at the key in config, returning defaultValue if the key is not set.
+func parseFloat(config map[string]string, key string, defaultValue float64) (float64, error) {
+	s, ok := config[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing %s %s: %w", key, s, err)
+	}
+	return value, nil
+}
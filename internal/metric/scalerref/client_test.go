@@ -0,0 +1,115 @@
+package scalerref
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+func newScaler(name, namespace string, desiredReplicas, currentReplicas int32, references ...string) *rrethyv1.HorizontalReplicaScaler {
+	scaler := &rrethyv1.HorizontalReplicaScaler{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status:     rrethyv1.HorizontalReplicaScalerStatus{DesiredReplicas: desiredReplicas, CurrentReplicas: currentReplicas},
+	}
+	for _, reference := range references {
+		scaler.Spec.Metrics = append(scaler.Spec.Metrics, rrethyv1.MetricSpec{
+			Type:   rrethyv1.ScalerRefMetricType,
+			Config: map[string]string{"name": reference},
+		})
+	}
+	return scaler
+}
+
+func TestClient_GetValue(t *testing.T) {
+	objects := []client.Object{
+		newScaler("backend", "default", 10, 8),
+		newScaler("backend", "other", 100, 100),
+		newScaler("frontend", "default", 5, 5, "backend"),
+		newScaler("edge", "default", 2, 2, "frontend", "backend", "missing"),
+		newScaler("a", "default", 1, 1, "b"),
+		newScaler("b", "default", 1, 1, "c"),
+		newScaler("c", "default", 1, 1, "a"),
+		newScaler("loops-elsewhere", "default", 3, 3, "b"),
+	}
+
+	tests := []struct {
+		testName      string
+		config        map[string]string
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{
+			testName:      "desired replicas is the default",
+			config:        map[string]string{"name": "backend"},
+			expectedValue: 10,
+		},
+		{
+			testName:      "current replicas",
+			config:        map[string]string{"name": "backend", "replicas": "current"},
+			expectedValue: 8,
+		},
+		{
+			testName:      "multiplier and offset",
+			config:        map[string]string{"name": "backend", "multiplier": "0.5", "offset": "2"},
+			expectedValue: 7,
+		},
+		{
+			testName:      "references without a cycle are followed",
+			config:        map[string]string{"name": "edge"},
+			expectedValue: 2,
+		},
+		{
+			testName:    "cycle is an error",
+			config:      map[string]string{"name": "a"},
+			expectedErr: true,
+		},
+		{
+			testName:    "cycle further along the references is an error",
+			config:      map[string]string{"name": "loops-elsewhere"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing scaler is an error",
+			config:      map[string]string{"name": "missing"},
+			expectedErr: true,
+		},
+		{
+			testName:    "unknown replicas is an error",
+			config:      map[string]string{"name": "backend", "replicas": "max"},
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid multiplier is an error",
+			config:      map[string]string{"name": "backend", "multiplier": "double"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing name is an error",
+			config:      map[string]string{},
+			expectedErr: true,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, rrethyv1.AddToScheme(scheme))
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			value, err := NewClient(reader).GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.ScalerRefMetricType, Config: test.config})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+		})
+	}
+}
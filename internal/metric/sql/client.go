@@ -0,0 +1,209 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"k8s.io/utils/clock"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+const (
+	// PostgresDriver connects with lib/pq.
+	PostgresDriver = "postgres"
+	// MySQLDriver connects with go-sql-driver/mysql.
+	MySQLDriver = "mysql"
+
+	defaultTimeout     = 10 * time.Second
+	maxOpenConnections = 4
+	maxIdleTime        = 5 * time.Minute
+	// poolIdleTimeout is how long a connection pool goes unused before it is closed, e.g. after its metric is removed
+	// or its dsn changes.
+	poolIdleTimeout = 30 * time.Minute
+)
+
+// Option is a function that configures a Client.
+type Option func(*Client)
+
+// WithClock sets the clock used by the client.
+// This is useful for testing where a fake clock is used.
+func WithClock(clock clock.PassiveClock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// pool is a connection pool along with when it was last used.
+type pool struct {
+	db       *sql.DB
+	lastUsed time.Time
+}
+
+// Client is a metric client which runs a scalar query against Postgres or MySQL.
+// Connection pools are shared across every metric with the same driver and dsn, and closed once unused for a while.
+type Client struct {
+	// open opens a connection pool and is mocked in tests.
+	open  func(driverName, dsn string) (*sql.DB, error)
+	clock clock.PassiveClock
+
+	mutex sync.Mutex
+	pools map[string]*pool
+}
+
+func NewClient(options ...Option) *Client {
+	c := &Client{
+		open:  sql.Open,
+		clock: clock.RealClock{},
+		pools: make(map[string]*pool),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// GetValue runs the "query" key in a read-only transaction and returns its result,
+// which must be a single row with a single non-null numeric column.
+// The "driver" key is one of postgres or mysql and the "dsn" key is the connection string,
+// usually from a Secret. The query is cancelled after the "timeout" key which defaults to 10s, and the same timeout
+// is set on the server so it stops running the query too.
+func (c *Client) GetValue(ctx context.Context, _ string, metric rrethyv1.MetricSpec) (float64, error) {
+	driver := metric.Config["driver"]
+	if driver != PostgresDriver && driver != MySQLDriver {
+		return 0, fmt.Errorf("driver must be one of %s or %s", PostgresDriver, MySQLDriver)
+	}
+	dsn := metric.Config["dsn"]
+	if dsn == "" {
+		return 0, errors.New("dsn is required")
+	}
+	query := metric.Config["query"]
+	if query == "" {
+		return 0, errors.New("query is required")
+	}
+	timeout := defaultTimeout
+	if t, ok := metric.Config["timeout"]; ok {
+		var err error
+		timeout, err = time.ParseDuration(t)
+		if err != nil {
+			return 0, fmt.Errorf("failed parsing timeout %s: %w", t, err)
+		}
+		if timeout <= 0 {
+			return 0, fmt.Errorf("timeout %s must be positive", t)
+		}
+	}
+
+	db, err := c.pool(driver, dsn)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// The transaction is read-only so a misconfigured query can't modify the database.
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return 0, fmt.Errorf("beginning read-only transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, statementTimeout(driver, timeout)); err != nil {
+		return 0, fmt.Errorf("setting statement timeout: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("running query: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("reading columns: %w", err)
+	}
+	if len(columns) != 1 {
+		return 0, fmt.Errorf("query must return a single column, got %d", len(columns))
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, fmt.Errorf("running query: %w", err)
+		}
+		return 0, errors.New("query returned no rows")
+	}
+	var value sql.NullFloat64
+	if err := rows.Scan(&value); err != nil {
+		return 0, fmt.Errorf("query must return a numeric value: %w", err)
+	}
+	if rows.Next() {
+		return 0, errors.New("query must return a single row")
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("running query: %w", err)
+	}
+	if !value.Valid {
+		return 0, errors.New("query returned null")
+	}
+
+	return value.Float64, nil
+}
+
+// statementTimeout returns the statement setting the server-side timeout of the queries of the transaction.
+// The postgres timeout only lasts for the transaction, while the mysql one lasts for the session, so it is set before
+// every query on the pooled connections.
+func statementTimeout(driver string, timeout time.Duration) string {
+	if driver == MySQLDriver {
+		return fmt.Sprintf("SET SESSION MAX_EXECUTION_TIME = %d", timeout.Milliseconds())
+	}
+	return fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())
+}
+
+// pool returns the connection pool for the driver and dsn, opening it if needed.
+// Pools which have been unused for the poolIdleTimeout are closed.
+func (c *Client) pool(driver, dsn string) (*sql.DB, error) {
+	c.mutex.Lock()
+	now := c.clock.Now()
+	var idle []*sql.DB
+	for key, p := range c.pools {
+		if now.Sub(p.lastUsed) >= poolIdleTimeout {
+			idle = append(idle, p.db)
+			delete(c.pools, key)
+		}
+	}
+	db, err := c.getOrOpen(driver, dsn, now)
+	c.mutex.Unlock()
+
+	// Close waits for any query still running on the pool, so it is done without holding the lock.
+	for _, db := range idle {
+		_ = db.Close()
+	}
+	return db, err
+}
+
+// getOrOpen returns the connection pool for the driver and dsn, opening it if needed, and marks it used at now.
+// The mutex must be held.
+func (c *Client) getOrOpen(driver, dsn string, now time.Time) (*sql.DB, error) {
+	key := driver + "\x00" + dsn
+	if p, ok := c.pools[key]; ok {
+		p.lastUsed = now
+		return p.db, nil
+	}
+
+	db, err := c.open(driver, dsn)
+	if err != nil {
+		// The dsn is omitted from the error since it usually contains credentials.
+		return nil, fmt.Errorf("opening %s connection pool: %w", driver, err)
+	}
+	db.SetMaxOpenConns(maxOpenConnections)
+	db.SetMaxIdleConns(maxOpenConnections)
+	db.SetConnMaxIdleTime(maxIdleTime)
+	c.pools[key] = &pool{db: db, lastUsed: now}
+	return db, nil
+}
@@ -0,0 +1,237 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	clock "k8s.io/utils/clock/testing"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+const queuedJobsQuery = "SELECT count(*) FROM jobs WHERE state='queued'"
+
+// expectStatementTimeout expects the statement setting the server-side timeout of the query.
+func expectStatementTimeout(mock sqlmock.Sqlmock, statement string) {
+	mock.ExpectExec(statement).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestClient_GetValue(t *testing.T) {
+	tests := []struct {
+		testName      string
+		config        map[string]string
+		expect        func(mock sqlmock.Sqlmock)
+		expectedValue float64
+		expectedErr   bool
+	}{
+		{
+			testName: "single numeric value",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStatementTimeout(mock, "SET LOCAL statement_timeout = 10000")
+				mock.ExpectQuery(queuedJobsQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(42)))
+				mock.ExpectRollback()
+			},
+			expectedValue: 42,
+		},
+		{
+			testName: "numeric strings are converted",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStatementTimeout(mock, "SET LOCAL statement_timeout = 10000")
+				mock.ExpectQuery(queuedJobsQuery).WillReturnRows(sqlmock.NewRows([]string{"avg"}).AddRow([]byte("12.5")))
+				mock.ExpectRollback()
+			},
+			expectedValue: 12.5,
+		},
+		{
+			testName: "multiple columns are an error",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStatementTimeout(mock, "SET LOCAL statement_timeout = 10000")
+				mock.ExpectQuery(queuedJobsQuery).WillReturnRows(sqlmock.NewRows([]string{"state", "count"}).AddRow("queued", int64(42)))
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+		{
+			testName: "multiple rows are an error",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStatementTimeout(mock, "SET LOCAL statement_timeout = 10000")
+				mock.ExpectQuery(queuedJobsQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)).AddRow(int64(2)))
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+		{
+			testName: "no rows is an error",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStatementTimeout(mock, "SET LOCAL statement_timeout = 10000")
+				mock.ExpectQuery(queuedJobsQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}))
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+		{
+			testName: "null is an error",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStatementTimeout(mock, "SET LOCAL statement_timeout = 10000")
+				mock.ExpectQuery(queuedJobsQuery).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+		{
+			testName: "non numeric value is an error",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStatementTimeout(mock, "SET LOCAL statement_timeout = 10000")
+				mock.ExpectQuery(queuedJobsQuery).WillReturnRows(sqlmock.NewRows([]string{"state"}).AddRow("queued"))
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+		{
+			testName: "query error is an error",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStatementTimeout(mock, "SET LOCAL statement_timeout = 10000")
+				mock.ExpectQuery(queuedJobsQuery).WillReturnError(errors.New("relation jobs does not exist"))
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+		{
+			testName: "query is cancelled after the timeout",
+			config:   map[string]string{"timeout": "10ms"},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStatementTimeout(mock, "SET LOCAL statement_timeout = 10")
+				mock.ExpectQuery(queuedJobsQuery).WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(42)))
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+		{
+			testName: "mysql sets the max execution time",
+			config:   map[string]string{"driver": "mysql", "dsn": "jobs@tcp(db)/jobs"},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStatementTimeout(mock, "SET SESSION MAX_EXECUTION_TIME = 10000")
+				mock.ExpectQuery(queuedJobsQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(42)))
+				mock.ExpectRollback()
+			},
+			expectedValue: 42,
+		},
+		{
+			testName: "statement timeout error is an error",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SET LOCAL statement_timeout = 10000").WillReturnError(errors.New("permission denied"))
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+		{
+			testName:    "unknown driver is an error",
+			config:      map[string]string{"driver": "sqlite"},
+			expect:      func(mock sqlmock.Sqlmock) {},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing dsn is an error",
+			config:      map[string]string{"dsn": ""},
+			expect:      func(mock sqlmock.Sqlmock) {},
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid timeout is an error",
+			config:      map[string]string{"timeout": "soon"},
+			expect:      func(mock sqlmock.Sqlmock) {},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+			test.expect(mock)
+
+			client := NewClient()
+			client.open = func(driverName, dsn string) (*sql.DB, error) { return db, nil }
+
+			config := map[string]string{"driver": "postgres", "dsn": "postgres://jobs@db/jobs", "query": queuedJobsQuery}
+			for key, value := range test.config {
+				config[key] = value
+			}
+
+			value, err := client.GetValue(context.Background(), "default", rrethyv1.MetricSpec{Type: rrethyv1.SQLMetricType, Config: config})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestClient_pool(t *testing.T) {
+	var opened []string
+	client := NewClient()
+	client.open = func(driverName, dsn string) (*sql.DB, error) {
+		opened = append(opened, driverName+" "+dsn)
+		db, _, err := sqlmock.New()
+		return db, err
+	}
+
+	first, err := client.pool("postgres", "postgres://a")
+	assert.NoError(t, err)
+	second, err := client.pool("postgres", "postgres://a")
+	assert.NoError(t, err)
+	assert.Same(t, first, second, "pools are shared for the same driver and dsn")
+
+	_, err = client.pool("mysql", "postgres://a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"postgres postgres://a", "mysql postgres://a"}, opened)
+}
+
+func TestClient_pool_ClosesIdlePools(t *testing.T) {
+	fakeClock := clock.NewFakePassiveClock(time.Date(1997, time.November, 7, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithClock(fakeClock))
+	mocks := make(map[string]sqlmock.Sqlmock)
+	client.open = func(driverName, dsn string) (*sql.DB, error) {
+		db, mock, err := sqlmock.New()
+		mocks[dsn] = mock
+		return db, err
+	}
+
+	_, err := client.pool("postgres", "postgres://idle")
+	assert.NoError(t, err)
+	_, err = client.pool("postgres", "postgres://busy")
+	assert.NoError(t, err)
+	mocks["postgres://idle"].ExpectClose()
+
+	fakeClock.SetTime(fakeClock.Now().Add(poolIdleTimeout / 2))
+	_, err = client.pool("postgres", "postgres://busy")
+	assert.NoError(t, err)
+	assert.Len(t, client.pools, 2, "pools are kept until they are idle for the timeout")
+
+	fakeClock.SetTime(fakeClock.Now().Add(poolIdleTimeout / 2))
+	_, err = client.pool("postgres", "postgres://busy")
+	assert.NoError(t, err)
+	assert.Len(t, client.pools, 1)
+	assert.Contains(t, client.pools, "postgres\x00postgres://busy")
+	assert.NoError(t, mocks["postgres://idle"].ExpectationsWereMet(), "idle pools are closed")
+}
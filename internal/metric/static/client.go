@@ -1,6 +1,8 @@
 package static
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -13,10 +15,13 @@ func NewClient() *Client {
 	return &Client{}
 }
 
-func (c *Client) GetValue(metric rrethyv1.MetricSpec) (float64, error) {
+func (c *Client) GetValue(_ context.Context, _ string, metric rrethyv1.MetricSpec) (float64, error) {
+	if metric.Target == nil {
+		return 0, errors.New("target is required")
+	}
 	target, err := strconv.ParseFloat(metric.Target.Value, 64)
 	if err != nil {
-		return 0, fmt.Errorf("failed parsing target value %s: %w", metric.Target, err)
+		return 0, fmt.Errorf("failed parsing target value %s: %w", metric.Target.Value, err)
 	}
 	return target, nil
 }
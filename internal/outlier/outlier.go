@@ -0,0 +1,85 @@
+package outlier
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrNoAcceptedValue is returned when a value is rejected before any value was accepted to use in its place.
+var ErrNoAcceptedValue = errors.New("no previous value to use instead")
+
+// Reason is why a value was rejected.
+type Reason string
+
+const (
+	// OutOfBounds is the reason for rejecting a value outside the valid bounds.
+	OutOfBounds Reason = "OutOfBounds"
+	// Spike is the reason for rejecting a value which deviates too far from the median of the recent values.
+	Spike Reason = "Spike"
+)
+
+// Filter rejects values outside of valid bounds and spikes from the recent values.
+type Filter struct {
+	// MinValid and MaxValid are the inclusive bounds of valid values. Nil bounds are unbounded.
+	MinValid, MaxValid *float64
+	// SpikeFactor is the factor a value may deviate from the median of the recent values by before it is a spike,
+	// in either direction. Spikes aren't filtered when it is 0.
+	SpikeFactor float64
+	// Samples is the number of recent values kept to compute the median from.
+	Samples int
+}
+
+// State is the state of a filter carried between values.
+type State struct {
+	// Recent is the most recent accepted values, oldest first.
+	Recent []float64
+	// Suspect is true when the previous value was rejected as a spike.
+	Suspect bool
+}
+
+// Apply filters the value, returning the value to use in its place along with the reason it was rejected,
+// or an empty reason if it was accepted, and the next state of the filter.
+// Rejected values are replaced by the most recent accepted value, and an error is returned if there is none.
+// A spike is only rejected once, so a spike which is confirmed by the next value is accepted along with it.
+func (f Filter) Apply(state State, value float64) (float64, Reason, State, error) {
+	state.Recent = slices.Clone(state.Recent)
+
+	if (f.MinValid != nil && value < *f.MinValid) || (f.MaxValid != nil && value > *f.MaxValid) {
+		return reject(state, value, OutOfBounds)
+	}
+
+	if f.SpikeFactor > 0 && len(state.Recent) > 0 && !state.Suspect {
+		median := median(state.Recent)
+		if median > 0 && (value > median*f.SpikeFactor || value < median/f.SpikeFactor) {
+			state.Suspect = true
+			return reject(state, value, Spike)
+		}
+	}
+
+	state.Suspect = false
+	state.Recent = append(state.Recent, value)
+	if len(state.Recent) > max(f.Samples, 1) {
+		state.Recent = state.Recent[len(state.Recent)-max(f.Samples, 1):]
+	}
+	return value, "", state, nil
+}
+
+// reject returns the most recent accepted value in place of the rejected value.
+func reject(state State, value float64, reason Reason) (float64, Reason, State, error) {
+	if len(state.Recent) == 0 {
+		return 0, reason, state, fmt.Errorf("value %v rejected as %s with %w", value, reason, ErrNoAcceptedValue)
+	}
+	return state.Recent[len(state.Recent)-1], reason, state, nil
+}
+
+// median returns the median of the values.
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2
+}
@@ -0,0 +1,120 @@
+package outlier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr(f float64) *float64 {
+	return &f
+}
+
+func TestFilter_Apply(t *testing.T) {
+	tests := []struct {
+		testName       string
+		filter         Filter
+		state          State
+		value          float64
+		expectedValue  float64
+		expectedReason Reason
+		expectedState  State
+		expectedErr    bool
+	}{
+		{
+			testName:      "value within bounds is accepted",
+			filter:        Filter{MinValid: ptr(0), MaxValid: ptr(100), Samples: 3},
+			state:         State{Recent: []float64{5}},
+			value:         100,
+			expectedValue: 100,
+			expectedState: State{Recent: []float64{5, 100}},
+		},
+		{
+			testName:       "value above the max is replaced by the previous value",
+			filter:         Filter{MaxValid: ptr(100), Samples: 3},
+			state:          State{Recent: []float64{5, 6}},
+			value:          1e9,
+			expectedValue:  6,
+			expectedReason: OutOfBounds,
+			expectedState:  State{Recent: []float64{5, 6}},
+		},
+		{
+			testName:       "value below the min is replaced by the previous value",
+			filter:         Filter{MinValid: ptr(0), Samples: 3},
+			state:          State{Recent: []float64{5}},
+			value:          -1,
+			expectedValue:  5,
+			expectedReason: OutOfBounds,
+			expectedState:  State{Recent: []float64{5}},
+		},
+		{
+			testName:    "value out of bounds without a previous value",
+			filter:      Filter{MaxValid: ptr(100), Samples: 3},
+			value:       1e9,
+			expectedErr: true,
+		},
+		{
+			testName:       "spike above the median is rejected",
+			filter:         Filter{SpikeFactor: 10, Samples: 3},
+			state:          State{Recent: []float64{4, 6, 5}},
+			value:          51,
+			expectedValue:  5,
+			expectedReason: Spike,
+			expectedState:  State{Recent: []float64{4, 6, 5}, Suspect: true},
+		},
+		{
+			testName:       "spike below the median is rejected",
+			filter:         Filter{SpikeFactor: 10, Samples: 3},
+			state:          State{Recent: []float64{40, 60, 50}},
+			value:          4,
+			expectedValue:  50,
+			expectedReason: Spike,
+			expectedState:  State{Recent: []float64{40, 60, 50}, Suspect: true},
+		},
+		{
+			testName:      "spike confirmed by the next value is accepted",
+			filter:        Filter{SpikeFactor: 10, Samples: 3},
+			state:         State{Recent: []float64{4, 6, 5}, Suspect: true},
+			value:         60,
+			expectedValue: 60,
+			expectedState: State{Recent: []float64{6, 5, 60}},
+		},
+		{
+			testName:      "value within the spike factor is accepted",
+			filter:        Filter{SpikeFactor: 10, Samples: 3},
+			state:         State{Recent: []float64{4, 6, 5}},
+			value:         49,
+			expectedValue: 49,
+			expectedState: State{Recent: []float64{6, 5, 49}},
+		},
+		{
+			testName:      "spikes aren't filtered from a median of 0",
+			filter:        Filter{SpikeFactor: 10, Samples: 3},
+			state:         State{Recent: []float64{0, 0}},
+			value:         100,
+			expectedValue: 100,
+			expectedState: State{Recent: []float64{0, 0, 100}},
+		},
+		{
+			testName:      "first value is accepted",
+			filter:        Filter{SpikeFactor: 10, Samples: 3},
+			value:         100,
+			expectedValue: 100,
+			expectedState: State{Recent: []float64{100}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			value, reason, state, err := test.filter.Apply(test.state, test.value)
+			if test.expectedErr {
+				assert.ErrorIs(t, err, ErrNoAcceptedValue)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, value)
+			assert.Equal(t, test.expectedReason, reason)
+			assert.Equal(t, test.expectedState, state)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package pid
+
+import (
+	"math"
+	"time"
+)
+
+// Gains are the parameters of a PID controller.
+type Gains struct {
+	// Kp, Ki and Kd are the proportional, integral and derivative gains.
+	Kp, Ki, Kd float64
+	// IntegralLimit bounds the absolute value of the integral to prevent windup. It is unlimited when not positive.
+	IntegralLimit float64
+	// DerivativeSmoothing is the fraction of the previous derivative kept when updating the derivative, in [0, 1).
+	// It is 0 for an unsmoothed derivative.
+	DerivativeSmoothing float64
+}
+
+// State is the state of a PID controller carried between updates.
+type State struct {
+	// Integral is the error integrated over time in seconds.
+	Integral float64
+	// Error is the error of the last update.
+	Error float64
+	// Derivative is the smoothed derivative of the error per second.
+	Derivative float64
+}
+
+// Update returns the output of the controller for the error dt after the previous update, along with the new state.
+// The integral and derivative are only updated when dt is positive, so the first update of a controller without a
+// previous update only uses the proportional term.
+func (g Gains) Update(state State, err float64, dt time.Duration) (float64, State) {
+	seconds := dt.Seconds()
+	if seconds > 0 {
+		state.Integral += err * seconds
+		if g.IntegralLimit > 0 {
+			state.Integral = math.Max(-g.IntegralLimit, math.Min(g.IntegralLimit, state.Integral))
+		}
+		derivative := (err - state.Error) / seconds
+		state.Derivative = g.DerivativeSmoothing*state.Derivative + (1-g.DerivativeSmoothing)*derivative
+	}
+	state.Error = err
+
+	return g.Kp*err + g.Ki*state.Integral + g.Kd*state.Derivative, state
+}
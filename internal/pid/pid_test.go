@@ -0,0 +1,91 @@
+package pid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGains_Update(t *testing.T) {
+	tests := []struct {
+		testName       string
+		gains          Gains
+		state          State
+		err            float64
+		dt             time.Duration
+		expectedOutput float64
+		expectedState  State
+	}{
+		{
+			testName:       "proportional only",
+			gains:          Gains{Kp: 2},
+			err:            0.5,
+			dt:             10 * time.Second,
+			expectedOutput: 1,
+			expectedState:  State{Integral: 5, Error: 0.5, Derivative: 0.05},
+		},
+		{
+			testName:       "first update only uses the proportional term",
+			gains:          Gains{Kp: 1, Ki: 1, Kd: 1},
+			err:            0.5,
+			expectedOutput: 0.5,
+			expectedState:  State{Error: 0.5},
+		},
+		{
+			testName:       "integral accumulates over time",
+			gains:          Gains{Ki: 0.1},
+			state:          State{Integral: 2, Error: 0.5},
+			err:            0.5,
+			dt:             10 * time.Second,
+			expectedOutput: 0.7,
+			expectedState:  State{Integral: 7, Error: 0.5},
+		},
+		{
+			testName:       "integral is limited",
+			gains:          Gains{Ki: 0.1, IntegralLimit: 4},
+			state:          State{Integral: 2, Error: 0.5},
+			err:            0.5,
+			dt:             10 * time.Second,
+			expectedOutput: 0.4,
+			expectedState:  State{Integral: 4, Error: 0.5},
+		},
+		{
+			testName:       "negative integral is limited",
+			gains:          Gains{Ki: 0.1, IntegralLimit: 4},
+			state:          State{Integral: -2, Error: -0.5},
+			err:            -0.5,
+			dt:             10 * time.Second,
+			expectedOutput: -0.4,
+			expectedState:  State{Integral: -4, Error: -0.5},
+		},
+		{
+			testName:       "derivative of the error",
+			gains:          Gains{Kd: 10},
+			state:          State{Error: 0.2},
+			err:            0.5,
+			dt:             10 * time.Second,
+			expectedOutput: 0.3,
+			expectedState:  State{Integral: 5, Error: 0.5, Derivative: 0.03},
+		},
+		{
+			testName:       "smoothed derivative",
+			gains:          Gains{Kd: 10, DerivativeSmoothing: 0.5},
+			state:          State{Error: 0.2, Derivative: 0.01},
+			err:            0.5,
+			dt:             10 * time.Second,
+			expectedOutput: 0.2,
+			expectedState:  State{Integral: 5, Error: 0.5, Derivative: 0.02},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			output, state := test.gains.Update(test.state, test.err, test.dt)
+			assert.InDelta(t, test.expectedOutput, output, 1e-9)
+			assert.InDelta(t, test.expectedState.Integral, state.Integral, 1e-9)
+			assert.InDelta(t, test.expectedState.Error, state.Error, 1e-9)
+			assert.InDelta(t, test.expectedState.Derivative, state.Derivative, 1e-9)
+		})
+	}
+}
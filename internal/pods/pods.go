@@ -0,0 +1,46 @@
+package pods
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// IsActive returns true if the pod is neither being deleted nor finished.
+func IsActive(pod *corev1.Pod) bool {
+	return pod.DeletionTimestamp == nil &&
+		pod.Status.Phase != corev1.PodSucceeded &&
+		pod.Status.Phase != corev1.PodFailed
+}
+
+// IsReady returns true if the pod has the Ready condition and started at least initializationPeriod before now.
+// Pods still in their initialization period often report skewed metrics, e.g. high CPU while warming up.
+func IsReady(pod *corev1.Pod, now time.Time, initializationPeriod time.Duration) bool {
+	if pod.Status.StartTime == nil || now.Sub(pod.Status.StartTime.Time) < initializationPeriod {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// Requests returns the sum of the requests for the resource of the containers of the pods.
+// If container is not empty, only the containers with that name are included.
+// CPU is in cores and every other resource is in its base unit, e.g. bytes for memory.
+func Requests(pods []corev1.Pod, resource corev1.ResourceName, container string) float64 {
+	var requests float64
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			if container != "" && c.Name != container {
+				continue
+			}
+			if request, ok := c.Resources.Requests[resource]; ok {
+				requests += request.AsApproximateFloat64()
+			}
+		}
+	}
+	return requests
+}
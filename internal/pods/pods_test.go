@@ -0,0 +1,107 @@
+package pods
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPod(phase corev1.PodPhase, containers ...corev1.Container) corev1.Pod {
+	return corev1.Pod{
+		Spec:   corev1.PodSpec{Containers: containers},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func newContainer(name, cpu, memory string) corev1.Container {
+	requests := corev1.ResourceList{}
+	if cpu != "" {
+		requests[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		requests[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{Requests: requests}}
+}
+
+func TestIsActive(t *testing.T) {
+	deleting := newPod(corev1.PodRunning)
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+
+	tests := []struct {
+		testName string
+		pod      corev1.Pod
+		expected bool
+	}{
+		{testName: "running pod", pod: newPod(corev1.PodRunning), expected: true},
+		{testName: "pending pod", pod: newPod(corev1.PodPending), expected: true},
+		{testName: "succeeded pod", pod: newPod(corev1.PodSucceeded), expected: false},
+		{testName: "failed pod", pod: newPod(corev1.PodFailed), expected: false},
+		{testName: "deleting pod", pod: deleting, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsActive(&test.pod))
+		})
+	}
+}
+
+func TestIsReady(t *testing.T) {
+	now := time.Date(1997, time.November, 7, 0, 0, 0, 0, time.UTC)
+	newStartedPod := func(startedAgo time.Duration, ready corev1.ConditionStatus) corev1.Pod {
+		pod := newPod(corev1.PodRunning)
+		pod.Status.StartTime = &metav1.Time{Time: now.Add(-startedAgo)}
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}
+		return pod
+	}
+
+	tests := []struct {
+		testName             string
+		pod                  corev1.Pod
+		initializationPeriod time.Duration
+		expected             bool
+	}{
+		{testName: "ready pod", pod: newStartedPod(time.Minute, corev1.ConditionTrue), expected: true},
+		{testName: "unready pod", pod: newStartedPod(time.Minute, corev1.ConditionFalse), expected: false},
+		{testName: "ready pod after the initialization period", pod: newStartedPod(time.Minute, corev1.ConditionTrue), initializationPeriod: 30 * time.Second, expected: true},
+		{testName: "ready pod in the initialization period", pod: newStartedPod(time.Minute, corev1.ConditionTrue), initializationPeriod: 5 * time.Minute, expected: false},
+		{testName: "pod that has not started", pod: newPod(corev1.PodPending), expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsReady(&test.pod, now, test.initializationPeriod))
+		})
+	}
+}
+
+func TestRequests(t *testing.T) {
+	pods := []corev1.Pod{
+		newPod(corev1.PodRunning, newContainer("app", "500m", "256Mi"), newContainer("sidecar", "100m", "")),
+		newPod(corev1.PodRunning, newContainer("app", "1", "1Gi"), newContainer("sidecar", "", "64Mi")),
+	}
+
+	tests := []struct {
+		testName  string
+		resource  corev1.ResourceName
+		container string
+		expected  float64
+	}{
+		{testName: "cpu of every container", resource: corev1.ResourceCPU, expected: 1.6},
+		{testName: "cpu of a named container", resource: corev1.ResourceCPU, container: "app", expected: 1.5},
+		{testName: "memory of every container", resource: corev1.ResourceMemory, expected: (256 + 1024 + 64) * 1024 * 1024},
+		{testName: "missing container", resource: corev1.ResourceCPU, container: "missing", expected: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			assert.InDelta(t, test.expected, Requests(pods, test.resource, test.container), 1e-9)
+		})
+	}
+}
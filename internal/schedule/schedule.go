@@ -0,0 +1,71 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+// parser parses standard 5 field cron expressions as well as descriptors such as @daily.
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Evaluate returns whether the schedule is active at now, along with the next time after now the schedule starts
+// or ends. The next time is zero if the schedule never changes again.
+func Evaluate(schedule rrethyv1.ReplicaSchedule, now time.Time) (active bool, next time.Time, err error) {
+	if schedule.Start != "" || schedule.End != "" {
+		return evaluateRecurring(schedule, now)
+	}
+	if schedule.StartTime == nil && schedule.EndTime == nil {
+		return false, time.Time{}, fmt.Errorf("schedule %s needs a start and end cron expression or a start or end time", schedule.Name)
+	}
+
+	if schedule.StartTime != nil && now.Before(schedule.StartTime.Time) {
+		return false, schedule.StartTime.Time, nil
+	}
+	if schedule.EndTime != nil {
+		if now.Before(schedule.EndTime.Time) {
+			return true, schedule.EndTime.Time, nil
+		}
+		return false, time.Time{}, nil
+	}
+	return true, time.Time{}, nil
+}
+
+// evaluateRecurring evaluates a schedule with a recurring window between a start and end cron expression.
+// The window is active when it ends before it next starts.
+func evaluateRecurring(schedule rrethyv1.ReplicaSchedule, now time.Time) (bool, time.Time, error) {
+	if schedule.Start == "" || schedule.End == "" {
+		return false, time.Time{}, fmt.Errorf("schedule %s needs both a start and end cron expression", schedule.Name)
+	}
+	if schedule.StartTime != nil || schedule.EndTime != nil {
+		return false, time.Time{}, fmt.Errorf("schedule %s can't have both cron expressions and start or end times", schedule.Name)
+	}
+
+	location := time.UTC
+	if schedule.Timezone != "" {
+		var err error
+		location, err = time.LoadLocation(schedule.Timezone)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("failed loading timezone %s: %w", schedule.Timezone, err)
+		}
+	}
+
+	start, err := parser.Parse(schedule.Start)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("failed parsing start of schedule %s: %w", schedule.Name, err)
+	}
+	end, err := parser.Parse(schedule.End)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("failed parsing end of schedule %s: %w", schedule.Name, err)
+	}
+
+	now = now.In(location)
+	nextStart, nextEnd := start.Next(now), end.Next(now)
+	if nextEnd.Before(nextStart) {
+		return true, nextEnd, nil
+	}
+	return false, nextStart, nil
+}
@@ -0,0 +1,131 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
+)
+
+// monday is a Monday at midnight UTC.
+var monday = time.Date(1997, time.November, 10, 0, 0, 0, 0, time.UTC)
+
+func timeAt(t time.Time) *metav1.Time {
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+func TestEvaluate(t *testing.T) {
+	businessHours := rrethyv1.ReplicaSchedule{Name: "business-hours", Start: "0 9 * * 1-5", End: "0 17 * * 1-5"}
+	event := rrethyv1.ReplicaSchedule{Name: "event", StartTime: timeAt(monday.Add(12 * time.Hour)), EndTime: timeAt(monday.Add(14 * time.Hour))}
+
+	tests := []struct {
+		testName       string
+		schedule       rrethyv1.ReplicaSchedule
+		now            time.Time
+		expectedActive bool
+		expectedNext   time.Time
+		expectedErr    bool
+	}{
+		{
+			testName:       "inside a recurring window",
+			schedule:       businessHours,
+			now:            monday.Add(10 * time.Hour),
+			expectedActive: true,
+			expectedNext:   monday.Add(17 * time.Hour),
+		},
+		{
+			testName:       "at the start of a recurring window",
+			schedule:       businessHours,
+			now:            monday.Add(9 * time.Hour),
+			expectedActive: true,
+			expectedNext:   monday.Add(17 * time.Hour),
+		},
+		{
+			testName:       "outside a recurring window",
+			schedule:       businessHours,
+			now:            monday.Add(18 * time.Hour),
+			expectedActive: false,
+			expectedNext:   monday.Add(33 * time.Hour),
+		},
+		{
+			testName:       "recurring window in a timezone",
+			schedule:       rrethyv1.ReplicaSchedule{Name: "toronto", Start: "0 9 * * 1-5", End: "0 17 * * 1-5", Timezone: "America/Toronto"},
+			now:            monday.Add(10 * time.Hour),
+			expectedActive: false,
+			expectedNext:   monday.Add(14 * time.Hour),
+		},
+		{
+			testName:       "before an absolute window",
+			schedule:       event,
+			now:            monday.Add(10 * time.Hour),
+			expectedActive: false,
+			expectedNext:   monday.Add(12 * time.Hour),
+		},
+		{
+			testName:       "inside an absolute window",
+			schedule:       event,
+			now:            monday.Add(12 * time.Hour),
+			expectedActive: true,
+			expectedNext:   monday.Add(14 * time.Hour),
+		},
+		{
+			testName:       "after an absolute window",
+			schedule:       event,
+			now:            monday.Add(14 * time.Hour),
+			expectedActive: false,
+		},
+		{
+			testName:       "absolute window without an end",
+			schedule:       rrethyv1.ReplicaSchedule{Name: "forever", StartTime: timeAt(monday)},
+			now:            monday.Add(time.Hour),
+			expectedActive: true,
+		},
+		{
+			testName:    "recurring window without an end",
+			schedule:    rrethyv1.ReplicaSchedule{Name: "invalid", Start: "0 9 * * 1-5"},
+			now:         monday,
+			expectedErr: true,
+		},
+		{
+			testName:    "cron expressions and absolute times",
+			schedule:    rrethyv1.ReplicaSchedule{Name: "invalid", Start: "0 9 * * 1-5", End: "0 17 * * 1-5", StartTime: timeAt(monday)},
+			now:         monday,
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid cron expression",
+			schedule:    rrethyv1.ReplicaSchedule{Name: "invalid", Start: "not a cron", End: "0 17 * * 1-5"},
+			now:         monday,
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid timezone",
+			schedule:    rrethyv1.ReplicaSchedule{Name: "invalid", Start: "0 9 * * 1-5", End: "0 17 * * 1-5", Timezone: "Nowhere/Special"},
+			now:         monday,
+			expectedErr: true,
+		},
+		{
+			testName:    "no window",
+			schedule:    rrethyv1.ReplicaSchedule{Name: "invalid"},
+			now:         monday,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			active, next, err := Evaluate(test.schedule, test.now)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedActive, active)
+			assert.True(t, test.expectedNext.Equal(next), "expected next %s, got %s", test.expectedNext, next)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package stabilization
 
 import (
+	"math"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -11,6 +13,10 @@ import (
 	rrethyv1 "github.com/RRethy/horizontalreplicascaler/api/v1"
 )
 
+// maxEvents is the number of events kept for each key. Older events are dropped beyond it, and a window which reached
+// it is treated as spanning the window duration.
+const maxEvents = 1024
+
 // RollingWindowType is the type of rolling window.
 type RollingWindowType byte
 
@@ -19,8 +25,21 @@ const (
 	MaxRollingWindow RollingWindowType = iota
 	// MinRollingWindow is a rolling window that keeps the min value over the duration.
 	MinRollingWindow
+	// PercentileRollingWindow is a rolling window that keeps a percentile of the values over the duration.
+	PercentileRollingWindow
+	// EWMARollingWindow is a rolling window that keeps an exponentially weighted moving average of the values over the
+	// duration, with the duration as its time constant.
+	EWMARollingWindow
 )
 
+// Statistic is how a rolling window stabilizes the values within it.
+type Statistic struct {
+	// Type is the type of rolling window.
+	Type RollingWindowType
+	// Percentile is the percentile of the values kept by a PercentileRollingWindow, from 1 to 100.
+	Percentile int32
+}
+
 // KeyFor returns a key for the given strings.
 // This provides a consistent way to generate keys for the RollingEvents map.
 func KeyFor(s ...string) string {
@@ -52,8 +71,16 @@ type Window struct {
 	// RollingEvents is the stabilization window.
 	// It is a map of keys to a list of events.
 	// Only events that are within the window duration,
-	// and can be the min/max are kept.
+	// and can be the min/max for the min/max rolling windows, are kept.
 	RollingEvents map[string][]rrethyv1.ScaleEvent
+	// Statistics is the statistic the events of each key were last stabilized with.
+	// The events of a key are cleared when it is stabilized with a different statistic,
+	// since the min/max rolling windows only keep some of the events.
+	Statistics map[string]Statistic
+	// Sorted is the values of the events of each key stabilized with a PercentileRollingWindow, in increasing order.
+	Sorted map[string][]int32
+	// Averages is the running average of each key stabilized with an EWMARollingWindow.
+	Averages map[string]float64
 }
 
 // NewWindow creates a new Window with the given rolling window type and options.
@@ -63,6 +90,9 @@ func NewWindow(rollingWindowType RollingWindowType, options ...Option) *Window {
 		Mutex:         sync.RWMutex{},
 		Type:          rollingWindowType,
 		RollingEvents: make(map[string][]rrethyv1.ScaleEvent),
+		Statistics:    make(map[string]Statistic),
+		Sorted:        make(map[string][]int32),
+		Averages:      make(map[string]float64),
 	}
 
 	for _, option := range options {
@@ -75,23 +105,48 @@ func NewWindow(rollingWindowType RollingWindowType, options ...Option) *Window {
 // Stabilize is a thread-safe method which adds an event to the rolling window for the given key,
 // and returns the stabilized value over the window duration if the window is at least windowDuration.
 // If the window is not at least windowDuration, it returns 0 and false.
+// The value is stabilized with the type of the window.
 // This function runs in amortized O(1) time.
 func (w *Window) Stabilize(key string, value int32, windowDuration time.Duration) (stabilized int32, ok bool) {
+	return w.StabilizeWith(key, value, windowDuration, Statistic{Type: w.Type})
+}
+
+// StabilizeWith is the same as Stabilize but stabilizes the value with the given statistic instead of the type of the window.
+// The min/max and EWMA rolling windows run in amortized O(1) time and the percentile rolling window in O(n) time,
+// where n is the number of events within the window duration, which is at most maxEvents.
+func (w *Window) StabilizeWith(key string, value int32, windowDuration time.Duration, statistic Statistic) (stabilized int32, ok bool) {
 	w.Mutex.Lock()
 	defer w.Mutex.Unlock()
 
 	window := w.RollingEvents[key]
+	sorted, hasSorted := w.Sorted[key]
+	average, hasAverage := w.Averages[key]
+	if previous, ok := w.Statistics[key]; ok && previous != statistic {
+		window, sorted, hasSorted, hasAverage = nil, nil, false, false
+	}
+	w.Statistics[key] = statistic
 	t := w.Clock.Now()
 
+	// The sorted values and running average are rebuilt from the events if they are missing.
+	if statistic.Type == PercentileRollingWindow && (!hasSorted || len(sorted) != len(window)) {
+		sorted = sortedValues(window)
+	}
+
 	// windowDuration being 0 is a bit of a special case. We would like to only keep the latest event.
 	// This is similar to how the stabilization window works in the Kubernetes HPA.
 	popped := windowDuration == 0
-	for len(window) > 0 && (windowDuration == 0 || window[0].Timestamp.Add(windowDuration).Before(t)) {
+	for len(window) > 0 && (windowDuration == 0 || window[0].Timestamp.Add(windowDuration).Before(t) || len(window) >= maxEvents) {
+		if statistic.Type == PercentileRollingWindow {
+			sorted = removeSorted(sorted, window[0].Value)
+		}
 		window = window[1:]
 		popped = true
 	}
+	if statistic.Type == EWMARollingWindow && !hasAverage && len(window) > 0 {
+		average = ewma(window, windowDuration)
+	}
 
-	switch w.Type {
+	switch statistic.Type {
 	case MaxRollingWindow:
 		for len(window) > 0 && window[len(window)-1].Value < value {
 			window = window[:len(window)-1]
@@ -100,15 +155,104 @@ func (w *Window) Stabilize(key string, value int32, windowDuration time.Duration
 		for len(window) > 0 && window[len(window)-1].Value > value {
 			window = window[:len(window)-1]
 		}
+	case PercentileRollingWindow:
+		i, _ := slices.BinarySearch(sorted, value)
+		sorted = slices.Insert(sorted, i, value)
+		w.Sorted[key] = sorted
+	case EWMARollingWindow:
+		// The average starts over once every event has left the window.
+		if len(window) == 0 {
+			average = float64(value)
+		} else {
+			elapsed := t.Sub(window[len(window)-1].Timestamp.Time)
+			alpha := 1 - math.Exp(-float64(elapsed)/float64(windowDuration))
+			average += alpha * (float64(value) - average)
+		}
+		w.Averages[key] = average
 	default:
 		panic("invalid rolling window type")
 	}
+	if statistic.Type != PercentileRollingWindow {
+		delete(w.Sorted, key)
+	}
+	if statistic.Type != EWMARollingWindow {
+		delete(w.Averages, key)
+	}
 
 	window = append(window, rrethyv1.ScaleEvent{Value: value, Timestamp: metav1.NewTime(t)})
 	w.RollingEvents[key] = window
 
-	if popped {
+	if !popped {
+		return 0, false
+	}
+	switch statistic.Type {
+	case PercentileRollingWindow:
+		return percentile(sorted, statistic.Percentile), true
+	case EWMARollingWindow:
+		return int32(math.Round(average)), true
+	default:
 		return window[0].Value, true
 	}
-	return 0, false
+}
+
+// sortedValues returns the values of the events in increasing order.
+func sortedValues(events []rrethyv1.ScaleEvent) []int32 {
+	values := make([]int32, len(events))
+	for i, event := range events {
+		values[i] = event.Value
+	}
+	slices.Sort(values)
+	return values
+}
+
+// removeSorted removes one occurrence of value from the sorted values.
+func removeSorted(sorted []int32, value int32) []int32 {
+	if i, found := slices.BinarySearch(sorted, value); found {
+		return slices.Delete(sorted, i, i+1)
+	}
+	return sorted
+}
+
+// percentile returns the nearest-rank percentile of the sorted values.
+func percentile(sorted []int32, p int32) int32 {
+	rank := int(math.Ceil(float64(min(max(p, 1), 100)) / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// ewma returns the exponentially weighted moving average of the values of the events, which are ordered by time,
+// with a time constant of windowDuration.
+func ewma(events []rrethyv1.ScaleEvent, windowDuration time.Duration) float64 {
+	average := float64(events[0].Value)
+	for i := 1; i < len(events); i++ {
+		elapsed := events[i].Timestamp.Sub(events[i-1].Timestamp.Time)
+		alpha := 1 - math.Exp(-float64(elapsed)/float64(windowDuration))
+		average += alpha * (float64(events[i].Value) - average)
+	}
+	return average
+}
+
+// DeleteFunc removes the events of every key for which remove returns true and returns the number of keys removed.
+func (w *Window) DeleteFunc(remove func(key string) bool) int {
+	w.Mutex.Lock()
+	defer w.Mutex.Unlock()
+
+	removed := 0
+	for key := range w.RollingEvents {
+		if remove(key) {
+			delete(w.RollingEvents, key)
+			delete(w.Statistics, key)
+			delete(w.Sorted, key)
+			delete(w.Averages, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Len returns the number of keys in the window.
+func (w *Window) Len() int {
+	w.Mutex.RLock()
+	defer w.Mutex.RUnlock()
+
+	return len(w.RollingEvents)
 }
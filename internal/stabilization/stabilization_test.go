@@ -1,6 +1,8 @@
 package stabilization
 
 import (
+	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -287,3 +289,185 @@ func TestWindow_Stabilize(t *testing.T) {
 		})
 	}
 }
+
+func TestWindow_StabilizeWith(t *testing.T) {
+	tests := []struct {
+		testName           string
+		initialEvents      map[string][]rrethyv1.ScaleEvent
+		initialStatistics  map[string]Statistic
+		currentTime        time.Time
+		value              int32
+		windowDuration     time.Duration
+		statistic          Statistic
+		expectedEvents     []rrethyv1.ScaleEvent
+		expectedStabilized int32
+		expectedOk         bool
+	}{
+		{
+			testName: "p90 rolling window keeps every event",
+			initialEvents: map[string][]rrethyv1.ScaleEvent{"foobar": {
+				{Value: 20, Timestamp: metav1.NewTime(initialTime.Add(-20 * time.Second))}, // we need an event outside the window
+				{Value: 3, Timestamp: initialTime},
+				{Value: 9, Timestamp: initialTime},
+				{Value: 1, Timestamp: initialTime},
+				{Value: 7, Timestamp: initialTime},
+				{Value: 5, Timestamp: initialTime},
+				{Value: 2, Timestamp: initialTime},
+				{Value: 8, Timestamp: initialTime},
+				{Value: 4, Timestamp: initialTime},
+				{Value: 6, Timestamp: initialTime},
+			}},
+			currentTime:    initialTime.Add(1 * time.Second),
+			value:          10,
+			windowDuration: 10 * time.Second,
+			statistic:      Statistic{Type: PercentileRollingWindow, Percentile: 90},
+			expectedEvents: []rrethyv1.ScaleEvent{
+				{Value: 3, Timestamp: initialTime},
+				{Value: 9, Timestamp: initialTime},
+				{Value: 1, Timestamp: initialTime},
+				{Value: 7, Timestamp: initialTime},
+				{Value: 5, Timestamp: initialTime},
+				{Value: 2, Timestamp: initialTime},
+				{Value: 8, Timestamp: initialTime},
+				{Value: 4, Timestamp: initialTime},
+				{Value: 6, Timestamp: initialTime},
+				{Value: 10, Timestamp: metav1.NewTime(initialTime.Add(1 * time.Second))},
+			},
+			expectedStabilized: 9,
+			expectedOk:         true,
+		},
+		{
+			testName: "p50 rolling window",
+			initialEvents: map[string][]rrethyv1.ScaleEvent{"foobar": {
+				{Value: 20, Timestamp: metav1.NewTime(initialTime.Add(-20 * time.Second))}, // we need an event outside the window
+				{Value: 3, Timestamp: initialTime},
+				{Value: 1, Timestamp: initialTime},
+				{Value: 4, Timestamp: initialTime},
+			}},
+			currentTime:    initialTime.Add(1 * time.Second),
+			value:          2,
+			windowDuration: 10 * time.Second,
+			statistic:      Statistic{Type: PercentileRollingWindow, Percentile: 50},
+			expectedEvents: []rrethyv1.ScaleEvent{
+				{Value: 3, Timestamp: initialTime},
+				{Value: 1, Timestamp: initialTime},
+				{Value: 4, Timestamp: initialTime},
+				{Value: 2, Timestamp: metav1.NewTime(initialTime.Add(1 * time.Second))},
+			},
+			expectedStabilized: 2,
+			expectedOk:         true,
+		},
+		{
+			testName: "ewma rolling window weighs events by time",
+			initialEvents: map[string][]rrethyv1.ScaleEvent{"foobar": {
+				{Value: 20, Timestamp: metav1.NewTime(initialTime.Add(-20 * time.Second))}, // we need an event outside the window
+				{Value: 4, Timestamp: initialTime},
+			}},
+			currentTime:    initialTime.Add(10 * time.Second),
+			value:          8,
+			windowDuration: 10 * time.Second,
+			statistic:      Statistic{Type: EWMARollingWindow},
+			expectedEvents: []rrethyv1.ScaleEvent{
+				{Value: 4, Timestamp: initialTime},
+				{Value: 8, Timestamp: metav1.NewTime(initialTime.Add(10 * time.Second))},
+			},
+			// 4 + (1 - e^-1) * (8 - 4) is about 6.53.
+			expectedStabilized: 7,
+			expectedOk:         true,
+		},
+		{
+			testName: "changing the statistic clears the events",
+			initialEvents: map[string][]rrethyv1.ScaleEvent{"foobar": {
+				{Value: 6, Timestamp: metav1.NewTime(initialTime.Add(-20 * time.Second))},
+				{Value: 5, Timestamp: initialTime},
+			}},
+			initialStatistics: map[string]Statistic{"foobar": {Type: MaxRollingWindow}},
+			currentTime:       initialTime.Add(1 * time.Second),
+			value:             2,
+			windowDuration:    10 * time.Second,
+			statistic:         Statistic{Type: PercentileRollingWindow, Percentile: 90},
+			expectedEvents: []rrethyv1.ScaleEvent{
+				{Value: 2, Timestamp: metav1.NewTime(initialTime.Add(1 * time.Second))},
+			},
+			expectedStabilized: 0,
+			expectedOk:         false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			w := NewWindow(MaxRollingWindow, WithClock(clock.NewFakeClock(test.currentTime)))
+			w.RollingEvents = test.initialEvents
+			if test.initialStatistics != nil {
+				w.Statistics = test.initialStatistics
+			}
+			stabilized, ok := w.StabilizeWith("foobar", test.value, test.windowDuration, test.statistic)
+			assert.Equal(t, test.expectedOk, ok)
+			assert.Equal(t, test.expectedStabilized, stabilized)
+			assert.Equal(t, test.expectedEvents, w.RollingEvents["foobar"])
+			assert.Equal(t, test.statistic, w.Statistics["foobar"])
+		})
+	}
+}
+
+func TestWindow_StabilizeWith_Sequence(t *testing.T) {
+	fakeClock := clock.NewFakeClock(initialTime.Time)
+	w := NewWindow(MaxRollingWindow, WithClock(fakeClock))
+	p50 := Statistic{Type: PercentileRollingWindow, Percentile: 50}
+	ewma := Statistic{Type: EWMARollingWindow}
+
+	for _, value := range []int32{5, 1, 9, 3} {
+		w.StabilizeWith("percentile", value, 3*time.Second, p50)
+		w.StabilizeWith("ewma", value, 3*time.Second, ewma)
+		fakeClock.Step(time.Second)
+	}
+	// 5 left the window, leaving 1, 9, 3 and 7.
+	stabilized, ok := w.StabilizeWith("percentile", 7, 3*time.Second, p50)
+	assert.True(t, ok)
+	assert.Equal(t, int32(3), stabilized)
+	assert.Equal(t, []int32{1, 3, 7, 9}, w.Sorted["percentile"])
+
+	// The running average keeps the weight of events which left the window.
+	stabilized, ok = w.StabilizeWith("ewma", 7, 3*time.Second, ewma)
+	assert.True(t, ok)
+	expected := 5.0
+	for _, value := range []float64{1, 9, 3, 7} {
+		expected += (1 - math.Exp(-1.0/3)) * (value - expected)
+	}
+	assert.Equal(t, int32(math.Round(expected)), stabilized)
+	assert.InDelta(t, expected, w.Averages["ewma"], 1e-9)
+}
+
+func TestWindow_StabilizeWith_MaxEvents(t *testing.T) {
+	fakeClock := clock.NewFakeClock(initialTime.Time)
+	w := NewWindow(MaxRollingWindow, WithClock(fakeClock))
+	statistic := Statistic{Type: PercentileRollingWindow, Percentile: 100}
+
+	for i := int32(0); i < maxEvents; i++ {
+		_, ok := w.StabilizeWith("foobar", maxEvents-i, time.Hour, statistic)
+		assert.False(t, ok)
+		fakeClock.Step(time.Millisecond)
+	}
+	// The oldest event is dropped once there are maxEvents of them, even though it is within the window duration.
+	stabilized, ok := w.StabilizeWith("foobar", 0, time.Hour, statistic)
+	assert.True(t, ok)
+	assert.Equal(t, int32(maxEvents-1), stabilized)
+	assert.Len(t, w.RollingEvents["foobar"], maxEvents)
+	assert.Len(t, w.Sorted["foobar"], maxEvents)
+}
+
+func TestWindow_DeleteFunc(t *testing.T) {
+	w := NewWindow(MaxRollingWindow, WithClock(clock.NewFakeClock(initialTime.Time)))
+	w.Stabilize(KeyFor("default", "foo", "deployment"), 1, 0)
+	w.Stabilize(KeyFor("default", "bar", "deployment"), 1, 0)
+	w.Stabilize(KeyFor("other", "foo", "deployment"), 1, 0)
+	assert.Equal(t, 3, w.Len())
+
+	removed := w.DeleteFunc(func(key string) bool { return strings.HasPrefix(key, KeyFor("default", "foo")+"/") })
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, 2, w.Len())
+	assert.NotContains(t, w.RollingEvents, KeyFor("default", "foo", "deployment"))
+	assert.NotContains(t, w.Statistics, KeyFor("default", "foo", "deployment"))
+	assert.Contains(t, w.RollingEvents, KeyFor("default", "bar", "deployment"))
+	assert.Contains(t, w.RollingEvents, KeyFor("other", "foo", "deployment"))
+}